@@ -0,0 +1,103 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sandwich-go/logbus"
+)
+
+var errEmptyDomain = errors.New("empty domain")
+
+// checkSetCurrent SetCurrent未更新任何记录时，区分domain不存在、当前id回退及当前id未变化
+func checkSetCurrent(ctx context.Context, admin AdminDriver, domain string, current uint64) error {
+	c, err := admin.GetCurrent(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if c > current {
+		return fmt.Errorf("%w: domain %s, current %d, set %d", ErrCurrentBackwards, domain, c, current)
+	}
+	return nil
+}
+
+func domainNotFound(domain string) error { return fmt.Errorf("%w: %s", ErrDomainNotFound, domain) }
+func domainExists(domain string) error   { return fmt.Errorf("%w: %s", ErrDomainExists, domain) }
+
+// builderAdmin Builder.Admin返回的AdminDriver，在Driver的基础上拒绝修改本Builder正在使用的domain
+type builderAdmin struct {
+	AdminDriver
+	builder *builder
+}
+
+func (b *builder) Admin() (AdminDriver, error) {
+	if err := b.checkAvailableFlag(); err != nil {
+		return nil, err
+	}
+	admin, ok := b.origin.(AdminDriver)
+	if !ok {
+		return nil, ErrAdminNotSupported
+	}
+	return &builderAdmin{AdminDriver: admin, builder: b}, nil
+}
+
+func (a *builderAdmin) check(domain string) error {
+	if domain == "" {
+		return errEmptyDomain
+	}
+	return a.builder.checkAvailableFlag()
+}
+
+func (a *builderAdmin) checkNotInUse(domain string) error {
+	if _, ok := a.builder.engineGetters.Load(domain); ok {
+		return fmt.Errorf("%w: %s", ErrDomainInUse, domain)
+	}
+	return nil
+}
+
+func (a *builderAdmin) ListDomains(ctx context.Context) ([]DomainInfo, error) {
+	if err := a.builder.checkAvailableFlag(); err != nil {
+		return nil, err
+	}
+	return a.AdminDriver.ListDomains(ctx)
+}
+
+func (a *builderAdmin) GetCurrent(ctx context.Context, domain string) (uint64, error) {
+	if err := a.check(domain); err != nil {
+		return 0, err
+	}
+	return a.AdminDriver.GetCurrent(ctx, domain)
+}
+
+func (a *builderAdmin) SetCurrent(ctx context.Context, domain string, current uint64, force bool) error {
+	if err := a.check(domain); err != nil {
+		return err
+	}
+	if force {
+		// 本进程中的Engine可能持有即将被回退的号段
+		if err := a.checkNotInUse(domain); err != nil {
+			return err
+		}
+		logbus.Warn(w("force set current"), logbus.String("domain", domain), logbus.Uint64("current", current))
+	}
+	return a.AdminDriver.SetCurrent(ctx, domain, current, force)
+}
+
+func (a *builderAdmin) CreateDomain(ctx context.Context, domain string, current uint64) error {
+	if err := a.check(domain); err != nil {
+		return err
+	}
+	return a.AdminDriver.CreateDomain(ctx, domain, current)
+}
+
+func (a *builderAdmin) DeleteDomain(ctx context.Context, domain string) error {
+	if err := a.check(domain); err != nil {
+		return err
+	}
+	if err := a.checkNotInUse(domain); err != nil {
+		return err
+	}
+	logbus.Warn(w("delete domain"), logbus.String("domain", domain))
+	return a.AdminDriver.DeleteDomain(ctx, domain)
+}
@@ -0,0 +1,58 @@
+package siid_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/siidtest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuilderAdmin(t *testing.T) {
+	Convey("builder admin", t, func() {
+		ctx := context.Background()
+		d := siidtest.NewDriver()
+		b := siid.NewWithDriver(d, siid.NewConfig(siid.WithDevelopment(false)))
+		_, err := b.Admin()
+		So(err, ShouldEqual, siid.ErrorDriverHasNotInited)
+		So(b.Prepare(ctx), ShouldBeNil)
+
+		admin, err := b.Admin()
+		So(err, ShouldBeNil)
+		So(admin.CreateDomain(ctx, "idle", 100), ShouldBeNil)
+		e, _ := b.Build("busy")
+		So(e.MustNext(), ShouldEqual, 30000001)
+
+		domains, err := admin.ListDomains(ctx)
+		So(err, ShouldBeNil)
+		So(len(domains), ShouldEqual, 2)
+		So(domains[0].Domain, ShouldEqual, "busy")
+
+		// 前移不受影响，回退及删除正在使用的domain被拒绝
+		So(admin.SetCurrent(ctx, "busy", 40000000, false), ShouldBeNil)
+		So(errors.Is(admin.SetCurrent(ctx, "busy", 100, false), siid.ErrCurrentBackwards), ShouldBeTrue)
+		So(errors.Is(admin.SetCurrent(ctx, "busy", 100, true), siid.ErrDomainInUse), ShouldBeTrue)
+		So(errors.Is(admin.DeleteDomain(ctx, "busy"), siid.ErrDomainInUse), ShouldBeTrue)
+
+		So(admin.SetCurrent(ctx, "idle", 50, true), ShouldBeNil)
+		So(admin.DeleteDomain(ctx, "idle"), ShouldBeNil)
+		_, err = admin.GetCurrent(ctx, "idle")
+		So(errors.Is(err, siid.ErrDomainNotFound), ShouldBeTrue)
+		_, err = admin.GetCurrent(ctx, "")
+		So(err, ShouldNotBeNil)
+
+		So(b.Destroy(ctx), ShouldBeNil)
+		_, err = admin.ListDomains(ctx)
+		So(err, ShouldEqual, siid.ErrorDriverHasClosed)
+
+		b = siid.NewWithDriver(siid.NewFailoverDriver(
+			siid.FailoverBackend{Name: "primary", Driver: d, Ceiling: 1 << 62},
+			siid.FailoverBackend{Name: "secondary", Driver: siidtest.NewDriver(), Offset: 1 << 62},
+		), siid.NewConfig())
+		So(b.Prepare(ctx), ShouldBeNil)
+		_, err = b.Admin()
+		So(err, ShouldEqual, siid.ErrAdminNotSupported)
+	})
+}
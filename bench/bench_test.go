@@ -41,7 +41,7 @@ func getMysqlDriver(address string) siid.Driver {
 	}
 }
 
-func BenchmarkSIID_MySQL(b *testing.B) {
+func getMysqlEngine(b *testing.B) siid.Engine {
 	initBenchmark()
 	bd := siid.New(mysqlDriverName, siid.NewConfig(
 		siid.WithDevelopment(false),
@@ -55,6 +55,11 @@ func BenchmarkSIID_MySQL(b *testing.B) {
 	if err0 != nil {
 		b.Fatal(err0)
 	}
+	return e
+}
+
+func BenchmarkSIID_MySQL(b *testing.B) {
+	e := getMysqlEngine(b)
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
@@ -62,6 +67,17 @@ func BenchmarkSIID_MySQL(b *testing.B) {
 	}
 }
 
+func BenchmarkParallelSIID_MySQL(b *testing.B) {
+	e := getMysqlEngine(b)
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = e.Next()
+		}
+	})
+}
+
 func BenchmarkRand(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -0,0 +1,136 @@
+package siid
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sandwich-go/logbus"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int32
+
+const (
+	CircuitClosed   CircuitState = iota // 正常
+	CircuitOpen                         // 熔断，renew直接返回ErrRenewCircuitOpen
+	CircuitHalfOpen                     // 半开，允许一次renew探测
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+type circuit struct {
+	state    CircuitState
+	failures uint
+	openedAt time.Time
+}
+
+// circuitBreaker 按domain熔断Driver.Renew
+// 连续失败threshold次后熔断，经过coolDown后进入半开状态，仅放行一次探测，成功则恢复，失败则重新熔断
+type circuitBreaker struct {
+	threshold uint
+	coolDown  time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+func newCircuitBreaker(threshold uint, coolDown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, coolDown: coolDown, circuits: make(map[string]*circuit)}
+}
+
+func (cb *circuitBreaker) get(domain string) *circuit {
+	c, ok := cb.circuits[domain]
+	if !ok {
+		c = &circuit{}
+		cb.circuits[domain] = c
+	}
+	return c
+}
+
+// State 返回domain的熔断状态，熔断已超过冷却时长时视为半开
+func (cb *circuitBreaker) State(domain string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c, ok := cb.circuits[domain]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && nowFunc().Sub(c.openedAt) >= cb.coolDown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+func (cb *circuitBreaker) allow(domain string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.get(domain)
+	switch c.state {
+	case CircuitOpen:
+		if nowFunc().Sub(c.openedAt) < cb.coolDown {
+			return ErrRenewCircuitOpen
+		}
+		// 冷却结束，放行本次探测，探测结束前其他renew仍被拒绝
+		c.state = CircuitHalfOpen
+	case CircuitHalfOpen:
+		return ErrRenewCircuitOpen
+	}
+	return nil
+}
+
+func (cb *circuitBreaker) done(domain string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.get(domain)
+	if err == nil {
+		if c.state != CircuitClosed {
+			logbus.Info(w("renew circuit closed"), logbus.String("domain", domain))
+		}
+		c.state, c.failures = CircuitClosed, 0
+		return
+	}
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= cb.threshold {
+		if c.state != CircuitOpen {
+			logbus.Warn(w("renew circuit open"), logbus.String("domain", domain), logbus.Uint("failures", c.failures), logbus.ErrorField(err))
+		}
+		c.state, c.openedAt = CircuitOpen, nowFunc()
+	}
+}
+
+// release 探测因调用方ctx结束而中断，不计入失败，重新进入熔断状态等待下一次探测
+func (cb *circuitBreaker) release(domain string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c := cb.get(domain); c.state == CircuitHalfOpen {
+		c.state = CircuitOpen
+	}
+}
+
+// middleware 熔断中间件，须位于重试中间件的内层，熔断后重试随即终止
+func (cb *circuitBreaker) middleware() DriverMiddleware {
+	return RenewMiddleware(func(next RenewFunc) RenewFunc {
+		return func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+			if err := cb.allow(domain); err != nil {
+				return 0, err
+			}
+			c, err := next(ctx, domain, quantum, offsetOnCreate)
+			if err != nil && ctx.Err() != nil {
+				// 调用方放弃等待，不代表Driver异常
+				cb.release(domain)
+				return c, err
+			}
+			cb.done(domain, err)
+			return c, err
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	Convey("circuit breaker", t, func() {
+		cb := newCircuitBreaker(2, 20*time.Millisecond)
+		So(cb.State("a"), ShouldEqual, CircuitClosed)
+		cb.done("a", errors.New("fail"))
+		So(cb.State("a"), ShouldEqual, CircuitClosed)
+		cb.done("a", errors.New("fail"))
+		So(cb.State("a"), ShouldEqual, CircuitOpen)
+		So(cb.State("b"), ShouldEqual, CircuitClosed)
+		So(cb.allow("a"), ShouldEqual, ErrRenewCircuitOpen)
+
+		time.Sleep(30 * time.Millisecond)
+		So(cb.State("a"), ShouldEqual, CircuitHalfOpen)
+		So(cb.allow("a"), ShouldBeNil)
+		// 半开状态只放行一次探测
+		So(cb.allow("a"), ShouldEqual, ErrRenewCircuitOpen)
+		cb.done("a", errors.New("fail"))
+		So(cb.State("a"), ShouldEqual, CircuitOpen)
+
+		time.Sleep(30 * time.Millisecond)
+		So(cb.allow("a"), ShouldBeNil)
+		cb.release("a")
+		So(cb.allow("a"), ShouldBeNil)
+		cb.done("a", nil)
+		So(cb.State("a"), ShouldEqual, CircuitClosed)
+	})
+}
+
+func TestCircuitBreakerNext(t *testing.T) {
+	Convey("next fails fast while circuit open", t, func() {
+		d := &flakyDriver{Driver: getDummyDriver(), fails: 1 << 30}
+		b := NewWithDriver(d, NewConfig(
+			WithCircuitBreakerThreshold(3),
+			WithCircuitBreakerCoolDown(50*time.Millisecond),
+			WithRenewRetryDelay(time.Millisecond),
+			WithDevelopment(false)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, _ := b.Build("circuit")
+
+		_, err := e.Next()
+		So(err, ShouldEqual, ErrRenewCircuitOpen)
+		So(d.calls, ShouldEqual, 3)
+		So(e.Stats().CircuitState, ShouldEqual, CircuitOpen)
+
+		begin := time.Now()
+		_, err = e.Next()
+		So(err, ShouldEqual, ErrRenewCircuitOpen)
+		So(time.Since(begin), ShouldBeLessThan, 10*time.Millisecond)
+		So(d.calls, ShouldEqual, 3)
+
+		d.fails = 0
+		time.Sleep(60 * time.Millisecond)
+		So(e.Stats().CircuitState, ShouldEqual, CircuitHalfOpen)
+		_, err = e.Next()
+		So(err, ShouldBeNil)
+		So(e.Stats().CircuitState, ShouldEqual, CircuitClosed)
+		So(b.Destroy(context.Background()), ShouldBeNil)
+	})
+}
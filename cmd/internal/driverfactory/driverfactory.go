@@ -0,0 +1,88 @@
+// Package driverfactory siid-server与siidctl共用的内置driver表，由driver名称、dsn及name新建driver
+package driverfactory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/sandwich-go/siid"
+	bolt "go.etcd.io/bbolt"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Factory 由dsn及name新建driver，name为database/schema/bucket/key前缀
+type Factory func(ctx context.Context, dsn, name string) (siid.Driver, error)
+
+var factories = map[string]Factory{
+	"mysql": func(_ context.Context, dsn, name string) (siid.Driver, error) {
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return nil, err
+		}
+		return siid.NewMysqlDriverWithName(db, name, name), nil
+	},
+	"postgres": func(_ context.Context, dsn, name string) (siid.Driver, error) {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, err
+		}
+		return siid.NewPostgresDriverWithName(db, name, name), nil
+	},
+	"mongo": func(ctx context.Context, dsn, name string) (siid.Driver, error) {
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(dsn))
+		if err != nil {
+			return nil, err
+		}
+		return siid.NewMongoDriverWithName(client, name, name), nil
+	},
+	"redis": func(_ context.Context, dsn, name string) (siid.Driver, error) {
+		opts, err := redis.ParseURL(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return siid.NewRedisDriverWithName(redis.NewClient(opts), name, false), nil
+	},
+	"bolt": func(_ context.Context, dsn, name string) (siid.Driver, error) {
+		db, err := bolt.Open(dsn, 0600, &bolt.Options{Timeout: time.Second})
+		if err != nil {
+			return nil, err
+		}
+		return siid.NewBoltDriverWithName(db, name), nil
+	},
+	// dsn为逗号分隔的etcd endpoints
+	"etcd": func(_ context.Context, dsn, name string) (siid.Driver, error) {
+		client, err := clientv3.New(clientv3.Config{Endpoints: strings.Split(dsn, ","), DialTimeout: 5 * time.Second})
+		if err != nil {
+			return nil, err
+		}
+		return siid.NewEtcdDriverWithPrefix(client, "/"+name+"/"), nil
+	},
+}
+
+// Names 返回内置driver名称，已排序
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for n := range factories {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New 按名称新建内置driver
+func New(ctx context.Context, driverName, dsn, name string) (siid.Driver, error) {
+	factory, ok := factories[driverName]
+	if !ok {
+		return nil, fmt.Errorf("unknown driver %q, builtin drivers %v", driverName, Names())
+	}
+	return factory(ctx, dsn, name)
+}
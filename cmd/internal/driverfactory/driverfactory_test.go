@@ -0,0 +1,27 @@
+package driverfactory
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	if got, want := Names(), []string{"bolt", "etcd", "mongo", "mysql", "postgres", "redis"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, err := New(context.Background(), "unknown", "", "siid"); err == nil {
+		t.Fatal("unknown driver should fail")
+	}
+	driver, err := New(context.Background(), "bolt", filepath.Join(t.TempDir(), "siid.db"), "siid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = driver.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = driver.Destroy(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
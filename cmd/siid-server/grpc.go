@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/cmd/siid-server/siidpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer 将siidpb.SIIDServer转发至siid.RemoteServer，与HTTP/JSON handler共用同一实现
+type grpcServer struct {
+	siidpb.UnimplementedSIIDServer
+	s *siid.RemoteServer
+}
+
+func newGRPCServer(s *siid.RemoteServer) *grpc.Server {
+	srv := grpc.NewServer()
+	siidpb.RegisterSIIDServer(srv, &grpcServer{s: s})
+	return srv
+}
+
+func (g *grpcServer) Renew(ctx context.Context, req *siidpb.RenewRequest) (*siidpb.RenewResponse, error) {
+	resp, err := g.s.Renew(ctx, &siid.RemoteRenewRequest{
+		Domain:         req.GetDomain(),
+		Quantum:        req.GetQuantum(),
+		OffsetOnCreate: req.GetOffsetOnCreate(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &siidpb.RenewResponse{Current: resp.Current}, nil
+}
+
+func (g *grpcServer) Next(ctx context.Context, req *siidpb.NextRequest) (*siidpb.NextResponse, error) {
+	resp, err := g.s.Next(ctx, &siid.RemoteNextRequest{Domain: req.GetDomain(), N: 1})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &siidpb.NextResponse{Id: resp.First}, nil
+}
+
+func (g *grpcServer) NextN(ctx context.Context, req *siidpb.NextNRequest) (*siidpb.NextNResponse, error) {
+	resp, err := g.s.Next(ctx, &siid.RemoteNextRequest{Domain: req.GetDomain(), N: req.GetN()})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &siidpb.NextNResponse{First: resp.First, Last: resp.Last}, nil
+}
+
+// grpcError 按siid.RemoteStatusCode将错误转换为gRPC状态码，与HTTP/JSON协议的错误分类一致
+func grpcError(err error) error {
+	code := codes.Internal
+	switch siid.RemoteStatusCode(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusConflict:
+		code = codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/cmd/siid-server/siidpb"
+	"github.com/sandwich-go/siid/siidtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCTestClient(t *testing.T, opts *siid.Options) siidpb.SIIDClient {
+	s := siid.NewRemoteServer(siidtest.NewDriver(), opts)
+	if err := s.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Destroy(context.Background()) })
+
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(s)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return siidpb.NewSIIDClient(conn)
+}
+
+func TestGRPCServer(t *testing.T) {
+	client := newGRPCTestClient(t, siid.NewConfig(
+		siid.WithOffsetWhenAutoCreateDomain(100),
+		siid.WithDevelopment(false)))
+	ctx := context.Background()
+
+	renewResp, err := client.Renew(ctx, &siidpb.RenewRequest{Domain: "renew", Quantum: 10, OffsetOnCreate: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewResp.GetCurrent() != 100 {
+		t.Fatalf("renew got current %d, want 100", renewResp.GetCurrent())
+	}
+
+	nextResp, err := client.Next(ctx, &siidpb.NextRequest{Domain: "next"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextResp.GetId() != 101 {
+		t.Fatalf("next got %d, want 101", nextResp.GetId())
+	}
+
+	nextNResp, err := client.NextN(ctx, &siidpb.NextNRequest{Domain: "next", N: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextNResp.GetFirst() != 102 || nextNResp.GetLast() != 106 {
+		t.Fatalf("next n got [%d, %d], want [102, 106]", nextNResp.GetFirst(), nextNResp.GetLast())
+	}
+
+	for name, call := range map[string]func() error{
+		"renew with empty domain": func() error {
+			_, err0 := client.Renew(ctx, &siidpb.RenewRequest{Quantum: 10})
+			return err0
+		},
+		"renew with zero quantum": func() error {
+			_, err0 := client.Renew(ctx, &siidpb.RenewRequest{Domain: "renew"})
+			return err0
+		},
+		"next with empty domain": func() error {
+			_, err0 := client.Next(ctx, &siidpb.NextRequest{})
+			return err0
+		},
+		"next n with empty domain": func() error {
+			_, err0 := client.NextN(ctx, &siidpb.NextNRequest{N: 5})
+			return err0
+		},
+	} {
+		if err = call(); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("%s got %v, want InvalidArgument", name, err)
+		}
+	}
+}
+
+func TestGRPCServerRegion(t *testing.T) {
+	client := newGRPCTestClient(t, siid.NewConfig(
+		siid.WithRegionCount(2),
+		siid.WithRegionIndex(1),
+		siid.WithDevelopment(false)))
+
+	_, err := client.NextN(context.Background(), &siidpb.NextNRequest{Domain: "region", N: 5})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("next n in region mode got %v, want InvalidArgument", err)
+	}
+}
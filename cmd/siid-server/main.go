@@ -0,0 +1,122 @@
+// siid-server 基于任意driver以HTTP/JSON及gRPC提供siid服务，gRPC协议见siidpb/siid.proto
+//
+//	siid-server -driver mysql -dsn "root:@tcp(127.0.0.1:3306)/mysql?charset=utf8" -http :8080 -grpc :9090
+//
+// Go服务使用siid.NewRemoteDriver("127.0.0.1:8080")，号段仍在本进程内缓存，只有siid-server访问数据库
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sandwich-go/logbus"
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/cmd/internal/driverfactory"
+)
+
+var (
+	driverName = flag.String("driver", "mysql", "driver name: mysql, postgres, mongo, redis, bolt, etcd or a registered driver")
+	dsn        = flag.String("dsn", "", "driver dsn, database url, bolt file path or comma separated etcd endpoints")
+	name       = flag.String("name", "siid", "database/schema/bucket/key prefix name")
+	httpAddr   = flag.String("http", ":8080", "HTTP/JSON listen address, empty to disable")
+	grpcAddr   = flag.String("grpc", ":9090", "gRPC listen address, empty to disable")
+	offset     = flag.Uint64("offset", 30000000, "offset when auto create domain")
+	maxQuantum = flag.Uint64("max-quantum", 3000, "max quantum a client may renew at once, should not be less than the clients' MaxQuantum")
+)
+
+func isRegistered(driverName string) bool {
+	for _, registered := range siid.Drivers() {
+		if registered == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+// newBuilder 按名称从siid的driver注册表中获取driver，未注册的内置driver以dsn新建后注册
+func newBuilder(ctx context.Context, driverName, dsn, name string, opts *siid.Options) (siid.Builder, error) {
+	if !isRegistered(driverName) {
+		driver, err := driverfactory.New(ctx, driverName, dsn, name)
+		if err != nil {
+			return nil, fmt.Errorf("%w, registered drivers %v", err, siid.Drivers())
+		}
+		siid.Register(driverName, driver)
+	}
+	return siid.New(driverName, opts), nil
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		logbus.Error("siid-server exit", logbus.ErrorField(err))
+		logbus.Close()
+		os.Exit(1)
+	}
+	logbus.Close()
+}
+
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b, err := newBuilder(ctx, *driverName, *dsn, *name, siid.NewConfig(
+		siid.WithOffsetWhenAutoCreateDomain(*offset),
+		siid.WithMaxQuantum(*maxQuantum),
+		siid.WithDevelopment(false)))
+	if err != nil {
+		return err
+	}
+	s := siid.NewRemoteServerWithBuilder(b)
+	if err = s.Prepare(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = s.Destroy(context.Background()) }()
+
+	errChan := make(chan error, 2)
+	var httpServer *http.Server
+	if *httpAddr != "" {
+		httpServer = &http.Server{Addr: *httpAddr, Handler: siid.NewRemoteHandler(s)}
+		go func() {
+			logbus.Info("siid-server http listening", logbus.String("addr", *httpAddr))
+			if err0 := httpServer.ListenAndServe(); err0 != nil && !errors.Is(err0, http.ErrServerClosed) {
+				errChan <- err0
+			}
+		}()
+	}
+	grpcServer := newGRPCServer(s)
+	if *grpcAddr != "" {
+		lis, err0 := net.Listen("tcp", *grpcAddr)
+		if err0 != nil {
+			if httpServer != nil {
+				_ = httpServer.Close()
+			}
+			return err0
+		}
+		go func() {
+			logbus.Info("siid-server grpc listening", logbus.String("addr", *grpcAddr))
+			if err1 := grpcServer.Serve(lis); err1 != nil {
+				errChan <- err1
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-errChan:
+	}
+	if httpServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = httpServer.Shutdown(shutdownCtx)
+		cancel()
+	}
+	grpcServer.GracefulStop()
+	return err
+}
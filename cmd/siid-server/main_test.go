@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/siidtest"
+)
+
+func TestNewBuilder(t *testing.T) {
+	opts := siid.NewConfig(siid.WithOffsetWhenAutoCreateDomain(100), siid.WithDevelopment(false))
+	if _, err := newBuilder(context.Background(), "unknown", "", "siid", opts); err == nil {
+		t.Fatal("unknown driver should fail")
+	}
+
+	// 已注册的driver优先于内置driver
+	siid.Register("siidtest", siidtest.NewDriver())
+	b, err := newBuilder(context.Background(), "siidtest", "", "siid", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := siid.NewRemoteServerWithBuilder(b)
+	if err = s.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.Next(context.Background(), &siid.RemoteNextRequest{Domain: "player"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.First != 101 {
+		t.Fatalf("got %d, want 101", resp.First)
+	}
+	_ = s.Destroy(context.Background())
+
+	// 内置driver以dsn新建后注册
+	b, err = newBuilder(context.Background(), "bolt", filepath.Join(t.TempDir(), "siid.db"), "siid", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = b.Destroy(context.Background())
+	if !isRegistered("bolt") {
+		t.Fatal("bolt should be registered")
+	}
+}
@@ -0,0 +1,4 @@
+// Package siidpb siid-server gRPC协议的生成代码，由siid.proto经buf及protoc-gen-go、protoc-gen-go-grpc生成
+package siidpb
+
+//go:generate buf generate --template buf.gen.yaml --path siid.proto
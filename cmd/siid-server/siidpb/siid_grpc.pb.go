@@ -0,0 +1,183 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: siid.proto
+
+package siidpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SIIDClient is the client API for SIID service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SIIDClient interface {
+	// Renew 申请号段，号段为(current, current+quantum]，quantum不能超过服务端的MaxQuantum
+	Renew(ctx context.Context, in *RenewRequest, opts ...grpc.CallOption) (*RenewResponse, error)
+	// Next 取1个id
+	Next(ctx context.Context, in *NextRequest, opts ...grpc.CallOption) (*NextResponse, error)
+	// NextN 取n个连续的id [first, last]，n为0时取1个，多区域模式下返回InvalidArgument
+	NextN(ctx context.Context, in *NextNRequest, opts ...grpc.CallOption) (*NextNResponse, error)
+}
+
+type sIIDClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSIIDClient(cc grpc.ClientConnInterface) SIIDClient {
+	return &sIIDClient{cc}
+}
+
+func (c *sIIDClient) Renew(ctx context.Context, in *RenewRequest, opts ...grpc.CallOption) (*RenewResponse, error) {
+	out := new(RenewResponse)
+	err := c.cc.Invoke(ctx, "/siid.v1.SIID/Renew", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sIIDClient) Next(ctx context.Context, in *NextRequest, opts ...grpc.CallOption) (*NextResponse, error) {
+	out := new(NextResponse)
+	err := c.cc.Invoke(ctx, "/siid.v1.SIID/Next", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sIIDClient) NextN(ctx context.Context, in *NextNRequest, opts ...grpc.CallOption) (*NextNResponse, error) {
+	out := new(NextNResponse)
+	err := c.cc.Invoke(ctx, "/siid.v1.SIID/NextN", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SIIDServer is the server API for SIID service.
+// All implementations must embed UnimplementedSIIDServer
+// for forward compatibility
+type SIIDServer interface {
+	// Renew 申请号段，号段为(current, current+quantum]，quantum不能超过服务端的MaxQuantum
+	Renew(context.Context, *RenewRequest) (*RenewResponse, error)
+	// Next 取1个id
+	Next(context.Context, *NextRequest) (*NextResponse, error)
+	// NextN 取n个连续的id [first, last]，n为0时取1个，多区域模式下返回InvalidArgument
+	NextN(context.Context, *NextNRequest) (*NextNResponse, error)
+	mustEmbedUnimplementedSIIDServer()
+}
+
+// UnimplementedSIIDServer must be embedded to have forward compatible implementations.
+type UnimplementedSIIDServer struct {
+}
+
+func (UnimplementedSIIDServer) Renew(context.Context, *RenewRequest) (*RenewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Renew not implemented")
+}
+func (UnimplementedSIIDServer) Next(context.Context, *NextRequest) (*NextResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Next not implemented")
+}
+func (UnimplementedSIIDServer) NextN(context.Context, *NextNRequest) (*NextNResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NextN not implemented")
+}
+func (UnimplementedSIIDServer) mustEmbedUnimplementedSIIDServer() {}
+
+// UnsafeSIIDServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SIIDServer will
+// result in compilation errors.
+type UnsafeSIIDServer interface {
+	mustEmbedUnimplementedSIIDServer()
+}
+
+func RegisterSIIDServer(s grpc.ServiceRegistrar, srv SIIDServer) {
+	s.RegisterService(&SIID_ServiceDesc, srv)
+}
+
+func _SIID_Renew_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SIIDServer).Renew(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/siid.v1.SIID/Renew",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SIIDServer).Renew(ctx, req.(*RenewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SIID_Next_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SIIDServer).Next(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/siid.v1.SIID/Next",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SIIDServer).Next(ctx, req.(*NextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SIID_NextN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NextNRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SIIDServer).NextN(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/siid.v1.SIID/NextN",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SIIDServer).NextN(ctx, req.(*NextNRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SIID_ServiceDesc is the grpc.ServiceDesc for SIID service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SIID_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "siid.v1.SIID",
+	HandlerType: (*SIIDServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Renew",
+			Handler:    _SIID_Renew_Handler,
+		},
+		{
+			MethodName: "Next",
+			Handler:    _SIID_Next_Handler,
+		},
+		{
+			MethodName: "NextN",
+			Handler:    _SIID_NextN_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "siid.proto",
+}
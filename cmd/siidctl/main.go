@@ -0,0 +1,251 @@
+// siidctl 管理siid的domain，代替手工修改数据库
+//
+//	siidctl -driver mysql -dsn "root:@tcp(127.0.0.1:3306)/mysql?charset=utf8" domains list
+//	siidctl -driver mysql -dsn ... domains show player
+//	siidctl -driver mysql -dsn ... domains create guild --offset 30000000
+//	siidctl -driver mysql -dsn ... domains bump player --to 40000000
+//	siidctl -driver mysql -dsn ... next player -n 100
+//	siidctl -driver mysql -dsn ... export > domains.json
+//	siidctl -driver mysql -dsn ... import < domains.json
+//
+// -driver 为mysql、postgres、mongo、redis、bolt或etcd，etcd的dsn为逗号分隔的endpoints
+// domains命令需要driver实现siid.AdminDriver
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/cmd/internal/driverfactory"
+)
+
+const usage = `usage: siidctl [flags] <command>
+
+commands:
+  domains list                       list all domains and their current id
+  domains show <domain>              show the current id of a domain
+  domains create <domain> --offset N create a domain with current id N
+  domains bump <domain> --to N       move the current id of a domain forward to N, --force to move backwards
+  next <domain> -n N                 allocate N ids and print one per line
+  export                             write all domains as JSON to stdout
+  import                             read domains as JSON from stdin, create missing ones and bump existing ones, --force to move backwards
+
+flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	fs := flag.NewFlagSet("siidctl", flag.ContinueOnError)
+	driverName := fs.String("driver", "mysql", "driver name: mysql, postgres, mongo, redis, bolt or etcd")
+	dsn := fs.String("dsn", "", "driver dsn, database url, bolt file path or comma separated etcd endpoints")
+	name := fs.String("name", "siid", "database/schema/bucket/key prefix name")
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	builder, err := newBuilder(ctx, *driverName, *dsn, *name)
+	if err == nil {
+		err = (&app{builder: builder, in: os.Stdin, out: os.Stdout}).run(ctx, fs.Args())
+		if err0 := builder.Destroy(context.Background()); err == nil {
+			err = err0
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "siidctl: %v\n", err)
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func newBuilder(ctx context.Context, driverName, dsn, name string) (siid.Builder, error) {
+	driver, err := driverfactory.New(ctx, driverName, dsn, name)
+	if err != nil {
+		return nil, err
+	}
+	builder := siid.NewWithDriver(driver, siid.NewConfig(siid.WithDevelopment(false), siid.WithEnableMonitor(false)))
+	if err = builder.Prepare(ctx); err != nil {
+		return nil, err
+	}
+	return builder, nil
+}
+
+type app struct {
+	builder siid.Builder
+	in      io.Reader
+	out     io.Writer
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "domains":
+		return a.domains(ctx, args[1:])
+	case "next":
+		return a.next(ctx, args[1:])
+	case "export":
+		return a.export(ctx)
+	case "import":
+		return a.importDomains(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+}
+
+// domainArgs 解析`<domain> [flags]`形式的参数
+func domainArgs(fs *flag.FlagSet, args []string) (string, error) {
+	if len(args) == 0 || args[0] == "" || args[0][0] == '-' {
+		return "", fmt.Errorf("%w: %s requires a domain", errUsage, fs.Name())
+	}
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args[1:]); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", errUsage, fs.Name(), err)
+	}
+	if fs.NArg() > 0 {
+		return "", fmt.Errorf("%w: %s: unexpected arguments %v", errUsage, fs.Name(), fs.Args())
+	}
+	return args[0], nil
+}
+
+func (a *app) domains(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: domains requires a subcommand", errUsage)
+	}
+	admin, err := a.builder.Admin()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "list":
+		domains, err := admin.ListDomains(ctx)
+		if err != nil {
+			return err
+		}
+		for _, info := range domains {
+			_, _ = fmt.Fprintf(a.out, "%s\t%d\n", info.Domain, info.Current)
+		}
+		return nil
+	case "show":
+		domain, err := domainArgs(flag.NewFlagSet("domains show", flag.ContinueOnError), args[1:])
+		if err != nil {
+			return err
+		}
+		current, err := admin.GetCurrent(ctx, domain)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(a.out, "%s\t%d\n", domain, current)
+		return nil
+	case "create":
+		fs := flag.NewFlagSet("domains create", flag.ContinueOnError)
+		offset := fs.Uint64("offset", 0, "current id of the new domain")
+		domain, err := domainArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return admin.CreateDomain(ctx, domain, *offset)
+	case "bump":
+		fs := flag.NewFlagSet("domains bump", flag.ContinueOnError)
+		to := fs.String("to", "", "new current id")
+		force := fs.Bool("force", false, "allow moving the current id backwards")
+		domain, err := domainArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		current, err := strconv.ParseUint(*to, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: domains bump: invalid --to %q", errUsage, *to)
+		}
+		return admin.SetCurrent(ctx, domain, current, *force)
+	}
+	return fmt.Errorf("%w: unknown domains subcommand %q", errUsage, args[0])
+}
+
+func (a *app) next(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("next", flag.ContinueOnError)
+	n := fs.Uint64("n", 1, "number of ids")
+	domain, err := domainArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	e, err := a.builder.Build(domain)
+	if err != nil {
+		return err
+	}
+	first, last, err := e.NextRange(ctx, *n)
+	if err != nil {
+		return err
+	}
+	for id := first; ; id++ {
+		_, _ = fmt.Fprintln(a.out, id)
+		if id == last {
+			return nil
+		}
+	}
+}
+
+func (a *app) export(ctx context.Context) error {
+	admin, err := a.builder.Admin()
+	if err != nil {
+		return err
+	}
+	domains, err := admin.ListDomains(ctx)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(a.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(domains)
+}
+
+// importDomains 导入export的结果，已存在的domain只会前移，需要回退的domain跳过，--force时可回退
+func (a *app) importDomains(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	force := fs.Bool("force", false, "allow moving current ids backwards")
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: import: %v", errUsage, err)
+	}
+	admin, err := a.builder.Admin()
+	if err != nil {
+		return err
+	}
+	var domains []siid.DomainInfo
+	if err = json.NewDecoder(a.in).Decode(&domains); err != nil {
+		return fmt.Errorf("decode domains: %w", err)
+	}
+	for _, info := range domains {
+		err = admin.CreateDomain(ctx, info.Domain, info.Current)
+		if errors.Is(err, siid.ErrDomainExists) {
+			err = admin.SetCurrent(ctx, info.Domain, info.Current, *force)
+		}
+		if errors.Is(err, siid.ErrCurrentBackwards) {
+			_, _ = fmt.Fprintf(a.out, "%s\t%d\tskipped: %v\n", info.Domain, info.Current, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("import domain %s: %w", info.Domain, err)
+		}
+		_, _ = fmt.Fprintf(a.out, "%s\t%d\n", info.Domain, info.Current)
+	}
+	return nil
+}
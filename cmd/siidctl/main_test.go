@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/siidtest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestApp(d siid.Driver) (*app, *bytes.Buffer) {
+	b := siid.NewWithDriver(d, siid.NewConfig(siid.WithDevelopment(false), siid.WithEnableMonitor(false)))
+	So(b.Prepare(context.Background()), ShouldBeNil)
+	out := &bytes.Buffer{}
+	return &app{builder: b, in: strings.NewReader(""), out: out}, out
+}
+
+func TestSiidctl(t *testing.T) {
+	Convey("siidctl", t, func() {
+		ctx := context.Background()
+		d := siidtest.NewDriver()
+		a, out := newTestApp(d)
+		run := func(args ...string) (string, error) {
+			out.Reset()
+			err := a.run(ctx, args)
+			return out.String(), err
+		}
+
+		_, err := run("domains", "create", "guild", "--offset", "100")
+		So(err, ShouldBeNil)
+		_, err = run("domains", "create", "player", "--offset=30000000")
+		So(err, ShouldBeNil)
+		_, err = run("domains", "create", "guild")
+		So(errors.Is(err, siid.ErrDomainExists), ShouldBeTrue)
+
+		s, err := run("domains", "list")
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "guild\t100\nplayer\t30000000\n")
+
+		_, err = run("domains", "bump", "guild", "--to", "200")
+		So(err, ShouldBeNil)
+		_, err = run("domains", "bump", "guild", "--to", "150")
+		So(errors.Is(err, siid.ErrCurrentBackwards), ShouldBeTrue)
+		s, err = run("domains", "show", "guild")
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "guild\t200\n")
+
+		s, err = run("next", "guild", "-n", "3")
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "201\n202\n203\n")
+		// next已使用guild，不能再强制回退
+		_, err = run("domains", "bump", "guild", "--to", "150", "--force")
+		So(errors.Is(err, siid.ErrDomainInUse), ShouldBeTrue)
+
+		exported, err := run("export")
+		So(err, ShouldBeNil)
+		So(exported, ShouldContainSubstring, `"Domain": "player"`)
+
+		Convey("import", func() {
+			target := siidtest.NewDriver()
+			So(target.CreateDomain(ctx, "player", 40000000), ShouldBeNil)
+			b, _ := newTestApp(target)
+			b.in = strings.NewReader(exported)
+			So(b.run(ctx, []string{"import"}), ShouldBeNil)
+			c, _ := target.GetCurrent(ctx, "player")
+			So(c, ShouldEqual, 40000000)
+			c, _ = target.GetCurrent(ctx, "guild")
+			So(c, ShouldBeGreaterThan, 200)
+
+			b.in = strings.NewReader(exported)
+			So(b.run(ctx, []string{"import", "--force"}), ShouldBeNil)
+			c, _ = target.GetCurrent(ctx, "player")
+			So(c, ShouldEqual, 30000000)
+		})
+
+		Convey("usage", func() {
+			for _, args := range [][]string{
+				nil,
+				{"unknown"},
+				{"domains"},
+				{"domains", "show"},
+				{"domains", "bump", "guild", "--to", "x"},
+				{"domains", "create", "guild", "extra"},
+				{"next", "-n", "3"},
+			} {
+				_, err = run(args...)
+				So(errors.Is(err, errUsage), ShouldBeTrue)
+			}
+		})
+	})
+}
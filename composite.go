@@ -0,0 +1,157 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandwich-go/boost/z"
+)
+
+var ErrCompositeTimeOverflow = errors.New("composite time prefix overflow")
+
+// CompositeLayout 组合id的位布局：高TimeBits位为时间前缀，低SequenceBits位为siid分配的序号
+// 时间前缀为距Epoch的TimeUnit数，序号全局唯一，因此组合id唯一且按TimeUnit粗粒度有序
+type CompositeLayout struct {
+	Epoch        time.Time
+	TimeUnit     time.Duration
+	TimeBits     uint
+	SequenceBits uint
+}
+
+// DefaultCompositeLayout 默认布局，共63位（兼容int64）：24位分钟级时间前缀（约31年），39位序号
+var DefaultCompositeLayout = CompositeLayout{
+	Epoch:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	TimeUnit:     time.Minute,
+	TimeBits:     24,
+	SequenceBits: 39,
+}
+
+func (l CompositeLayout) validate() error {
+	if l.TimeBits == 0 || l.SequenceBits == 0 || l.TimeBits+l.SequenceBits > 64 {
+		return fmt.Errorf("invalid composite layout, time bits %d, sequence bits %d", l.TimeBits, l.SequenceBits)
+	}
+	if l.TimeUnit <= 0 {
+		return fmt.Errorf("invalid composite layout, time unit %s", l.TimeUnit)
+	}
+	return nil
+}
+
+func (l CompositeLayout) maxSequence() uint64 { return 1<<l.SequenceBits - 1 }
+func (l CompositeLayout) maxPrefix() uint64   { return 1<<l.TimeBits - 1 }
+
+// CompositeParts 组合id的组成部分
+type CompositeParts struct {
+	TimePrefix uint64
+	Time       time.Time // 时间前缀对应的时间，精度为TimeUnit
+	Sequence   uint64
+}
+
+// Explain 拆分组合id
+func (l CompositeLayout) Explain(id uint64) CompositeParts {
+	prefix := id >> l.SequenceBits
+	return CompositeParts{
+		TimePrefix: prefix,
+		Time:       l.Epoch.Add(time.Duration(prefix) * l.TimeUnit),
+		Sequence:   id & l.maxSequence(),
+	}
+}
+
+// CompositeEngine 在Engine的基础上生成时间有序的组合id
+// 创建时读取一次系统时间，此后时间前缀由单调时钟z.MonoOffset推进，不受系统时钟回拨影响，只增不减
+type CompositeEngine struct {
+	engine Engine
+	layout CompositeLayout
+	start  time.Duration // 创建时距Epoch的时长
+	begin  z.MonoTimeDuration
+	since  func(z.MonoTimeDuration) time.Duration
+	prefix uint64 // 已使用的最大时间前缀
+}
+
+// NewCompositeEngine 新建CompositeEngine
+func NewCompositeEngine(e Engine, layout CompositeLayout) (*CompositeEngine, error) {
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+	start := nowFunc().Sub(layout.Epoch)
+	if start < 0 {
+		return nil, fmt.Errorf("composite epoch %s is in the future", layout.Epoch)
+	}
+	return &CompositeEngine{engine: e, layout: layout, start: start, begin: z.MonoOffset(), since: z.MonoSince}, nil
+}
+
+// Layout 返回组合id的位布局
+func (c *CompositeEngine) Layout() CompositeLayout { return c.layout }
+
+// Explain 拆分组合id
+func (c *CompositeEngine) Explain(id uint64) CompositeParts { return c.layout.Explain(id) }
+
+func (c *CompositeEngine) timePrefix() (uint64, error) {
+	prefix := uint64((c.start + c.since(c.begin)) / c.layout.TimeUnit)
+	if prefix > c.layout.maxPrefix() {
+		return 0, fmt.Errorf("%w: prefix %d, time bits %d", ErrCompositeTimeOverflow, prefix, c.layout.TimeBits)
+	}
+	for {
+		last := atomic.LoadUint64(&c.prefix)
+		if prefix <= last {
+			return last, nil
+		}
+		if atomic.CompareAndSwapUint64(&c.prefix, last, prefix) {
+			return prefix, nil
+		}
+	}
+}
+
+func (c *CompositeEngine) compose(seq uint64) (uint64, error) {
+	if seq > c.layout.maxSequence() {
+		return 0, fmt.Errorf("%w: sequence %d, sequence bits %d", ErrReachIdLimitation, seq, c.layout.SequenceBits)
+	}
+	prefix, err := c.timePrefix()
+	if err != nil {
+		return 0, err
+	}
+	return prefix<<c.layout.SequenceBits | seq, nil
+}
+
+func (c *CompositeEngine) Next() (uint64, error) {
+	return c.NextContext(context.Background())
+}
+
+func (c *CompositeEngine) NextContext(ctx context.Context) (uint64, error) {
+	return c.NextNContext(ctx, 1)
+}
+
+func (c *CompositeEngine) NextNContext(ctx context.Context, n int) (uint64, error) {
+	seq, err := c.engine.NextNContext(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	return c.compose(seq)
+}
+
+func (c *CompositeEngine) MustNext() uint64 {
+	id, err := c.Next()
+	panicIfErr(err)
+	return id
+}
+
+// NextRange 区间内的组合id使用相同的时间前缀，因此仍然连续
+func (c *CompositeEngine) NextRange(ctx context.Context, n uint64) (first, last uint64, err error) {
+	first, last, err = c.engine.NextRange(ctx, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	if last > c.layout.maxSequence() {
+		return 0, 0, fmt.Errorf("%w: sequence %d, sequence bits %d", ErrReachIdLimitation, last, c.layout.SequenceBits)
+	}
+	prefix, err := c.timePrefix()
+	if err != nil {
+		return 0, 0, err
+	}
+	return prefix<<c.layout.SequenceBits | first, prefix<<c.layout.SequenceBits | last, nil
+}
+
+// Stats 返回内部Engine的状态，Current及Max为序号
+func (c *CompositeEngine) Stats() Stats { return c.engine.Stats() }
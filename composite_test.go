@@ -0,0 +1,75 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandwich-go/boost/z"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompositeEngine(t *testing.T) {
+	Convey("composite engine", t, func() {
+		b := NewWithDriver(getDummyDriver(), NewConfig(WithDevelopment(false)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, _ := b.Build("composite")
+
+		layout := CompositeLayout{Epoch: time.Now().Add(-10 * time.Second), TimeUnit: time.Second, TimeBits: 20, SequenceBits: 40}
+		ce, err := NewCompositeEngine(e, layout)
+		So(err, ShouldBeNil)
+		var _ Engine = ce
+
+		id := ce.MustNext()
+		parts := ce.Explain(id)
+		So(parts.Sequence, ShouldEqual, 30000001)
+		So(parts.TimePrefix, ShouldBeBetweenOrEqual, 10, 11)
+		So(parts.Time, ShouldEqual, layout.Epoch.Add(time.Duration(parts.TimePrefix)*time.Second))
+
+		Convey("time prefix only moves forward", func() {
+			var elapsed time.Duration
+			ce.since = func(z.MonoTimeDuration) time.Duration { return elapsed }
+			elapsed = time.Hour
+			later := ce.MustNext()
+			So(later, ShouldBeGreaterThan, id)
+			So(ce.Explain(later).TimePrefix, ShouldEqual, 3610)
+			elapsed = 0
+			next := ce.MustNext()
+			So(ce.Explain(next).TimePrefix, ShouldEqual, 3610)
+			So(next, ShouldEqual, later+1)
+
+			elapsed = time.Duration(1<<20) * time.Second
+			_, err = ce.Next()
+			So(errors.Is(err, ErrCompositeTimeOverflow), ShouldBeTrue)
+		})
+
+		Convey("range", func() {
+			first, last, err := ce.NextRange(context.Background(), 10)
+			So(err, ShouldBeNil)
+			So(last-first, ShouldEqual, 9)
+			So(ce.Explain(first).Sequence, ShouldEqual, 30000002)
+		})
+
+		Convey("sequence overflow", func() {
+			small, err := NewCompositeEngine(e, CompositeLayout{Epoch: layout.Epoch, TimeUnit: time.Second, TimeBits: 20, SequenceBits: 20})
+			So(err, ShouldBeNil)
+			_, err = small.Next()
+			So(errors.Is(err, ErrReachIdLimitation), ShouldBeTrue)
+		})
+
+		Convey("invalid layout", func() {
+			for _, l := range []CompositeLayout{
+				{Epoch: layout.Epoch, TimeUnit: time.Second, TimeBits: 30, SequenceBits: 40},
+				{Epoch: layout.Epoch, TimeUnit: time.Second, SequenceBits: 40},
+				{Epoch: layout.Epoch, TimeBits: 20, SequenceBits: 40},
+				{Epoch: time.Now().Add(time.Hour), TimeUnit: time.Second, TimeBits: 20, SequenceBits: 40},
+			} {
+				_, err = NewCompositeEngine(e, l)
+				So(err, ShouldNotBeNil)
+			}
+			_, err = NewCompositeEngine(e, DefaultCompositeLayout)
+			So(err, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,119 @@
+package siid
+
+import "time"
+
+// DomainOption domain级别的配置，覆盖Builder的Options中的同名配置
+// 只在Engine创建时生效，Engine已存在时忽略
+type DomainOption func(*domainVisitor)
+
+// WithDomainMinQuantum 覆盖MinQuantum
+func WithDomainMinQuantum(v uint64) DomainOption {
+	return func(d *domainVisitor) { d.minQuantum = &v }
+}
+
+// WithDomainMaxQuantum 覆盖MaxQuantum
+func WithDomainMaxQuantum(v uint64) DomainOption {
+	return func(d *domainVisitor) { d.maxQuantum = &v }
+}
+
+// WithDomainInitialQuantum 覆盖InitialQuantum
+func WithDomainInitialQuantum(v uint64) DomainOption {
+	return func(d *domainVisitor) { d.initialQuantum = &v }
+}
+
+// WithDomainRenewPercent 覆盖RenewPercent
+func WithDomainRenewPercent(v int) DomainOption {
+	return func(d *domainVisitor) { d.renewPercent = &v }
+}
+
+// WithDomainLimitation 覆盖Limitation
+func WithDomainLimitation(v uint64) DomainOption {
+	return func(d *domainVisitor) { d.limitation = &v }
+}
+
+// WithDomainSegmentDuration 覆盖SegmentDuration
+func WithDomainSegmentDuration(v time.Duration) DomainOption {
+	return func(d *domainVisitor) { d.segmentDuration = &v }
+}
+
+// WithDomainOffset 覆盖OffsetWhenAutoCreateDomain，BuildWithOffset指定的非0 offset优先
+func WithDomainOffset(v uint64) DomainOption {
+	return func(d *domainVisitor) { d.offset = &v }
+}
+
+// domainVisitor 在Builder的OptionsVisitor上叠加domain级别的配置，未覆盖的配置仍读取Builder的OptionsVisitor
+type domainVisitor struct {
+	OptionsVisitor
+	minQuantum      *uint64
+	maxQuantum      *uint64
+	initialQuantum  *uint64
+	renewPercent    *int
+	limitation      *uint64
+	segmentDuration *time.Duration
+	offset          *uint64
+}
+
+// newDomainVisitor 依次应用Options.DomainOptions中domain的配置及opts，没有任何覆盖时返回visitor本身
+func newDomainVisitor(visitor OptionsVisitor, domain string, opts ...DomainOption) OptionsVisitor {
+	defaults := visitor.GetDomainOptions()[domain]
+	if len(defaults) == 0 && len(opts) == 0 {
+		return visitor
+	}
+	d := &domainVisitor{OptionsVisitor: visitor}
+	for _, opt := range defaults {
+		opt(d)
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func (d *domainVisitor) GetMinQuantum() uint64 {
+	if d.minQuantum != nil {
+		return *d.minQuantum
+	}
+	return d.OptionsVisitor.GetMinQuantum()
+}
+
+func (d *domainVisitor) GetMaxQuantum() uint64 {
+	if d.maxQuantum != nil {
+		return *d.maxQuantum
+	}
+	return d.OptionsVisitor.GetMaxQuantum()
+}
+
+func (d *domainVisitor) GetInitialQuantum() uint64 {
+	if d.initialQuantum != nil {
+		return *d.initialQuantum
+	}
+	return d.OptionsVisitor.GetInitialQuantum()
+}
+
+func (d *domainVisitor) GetRenewPercent() int {
+	if d.renewPercent != nil {
+		return *d.renewPercent
+	}
+	return d.OptionsVisitor.GetRenewPercent()
+}
+
+func (d *domainVisitor) GetLimitation() uint64 {
+	if d.limitation != nil {
+		return *d.limitation
+	}
+	return d.OptionsVisitor.GetLimitation()
+}
+
+func (d *domainVisitor) GetSegmentDuration() time.Duration {
+	if d.segmentDuration != nil {
+		return *d.segmentDuration
+	}
+	return d.OptionsVisitor.GetSegmentDuration()
+}
+
+func (d *domainVisitor) GetOffsetWhenAutoCreateDomain() uint64 {
+	if d.offset != nil {
+		return *d.offset
+	}
+	return d.OptionsVisitor.GetOffsetWhenAutoCreateDomain()
+}
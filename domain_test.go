@@ -0,0 +1,66 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDomainOptions(t *testing.T) {
+	Convey("domain options", t, func() {
+		b := NewWithDriver(getDummyDriver(), NewConfig(
+			WithOffsetWhenAutoCreateDomain(0), WithMinQuantum(10), WithDevelopment(false),
+			WithDomainOptions(map[string][]DomainOption{
+				"order": {WithDomainOffset(1000), WithDomainMinQuantum(100)},
+			}),
+		))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		defer func() { _ = b.Destroy(context.Background()) }()
+
+		Convey("defaults", func() {
+			e, err := b.Build("player")
+			So(err, ShouldBeNil)
+			So(e.MustNext(), ShouldEqual, 1)
+			So(e.Stats().Max, ShouldEqual, 30)
+		})
+
+		Convey("options map", func() {
+			e, err := b.Build("order")
+			So(err, ShouldBeNil)
+			So(e.MustNext(), ShouldEqual, 1001)
+			So(e.Stats().Max, ShouldEqual, 1100)
+		})
+
+		Convey("build options override options map", func() {
+			e, err := b.BuildWithOptions("order", WithDomainInitialQuantum(20), WithDomainMinQuantum(20), WithDomainLimitation(1003))
+			So(err, ShouldBeNil)
+			So(e.MustNext(), ShouldEqual, 1001)
+			So(e.Stats().Max, ShouldEqual, 1020)
+			So(e.MustNext(), ShouldEqual, 1002)
+			So(e.MustNext(), ShouldEqual, 1003)
+			_, err = e.Next()
+			So(err, ShouldEqual, ErrReachIdLimitation)
+
+			Convey("ignored once engine exists", func() {
+				e2, err := b.BuildWithOptions("order", WithDomainLimitation(0))
+				So(err, ShouldBeNil)
+				So(e2, ShouldEqual, e)
+			})
+		})
+
+		Convey("explicit offset wins", func() {
+			e, err := b.BuildWithOffset("order", 5000)
+			So(err, ShouldBeNil)
+			So(e.MustNext(), ShouldEqual, 5001)
+		})
+
+		Convey("invalid region", func() {
+			rb := NewWithDriver(getDummyDriver(), NewConfig(WithRegionCount(4), WithRegionIndex(3), WithDevelopment(false)))
+			So(rb.Prepare(context.Background()), ShouldBeNil)
+			_, err := rb.BuildWithOptions("order", WithDomainLimitation(2))
+			So(errors.Is(err, ErrInvalidRegion), ShouldBeTrue)
+		})
+	})
+}
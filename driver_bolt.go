@@ -0,0 +1,92 @@
+package siid
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bbolt同一时刻只有一个读写事务，Renew在读写事务中完成，天然串行化
+// 适合单节点部署、边缘工具及本地开发，id持久化在本地文件中
+type boltDriver struct {
+	bucketName []byte
+	db         *bolt.DB
+}
+
+func NewBoltDriver(db *bolt.DB) Driver {
+	return NewBoltDriverWithName(db, defaultName)
+}
+
+func NewBoltDriverWithName(db *bolt.DB, bucketName string) Driver {
+	return &boltDriver{db: db, bucketName: []byte(bucketName)}
+}
+
+func (d *boltDriver) Prepare(_ context.Context) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(d.bucketName)
+		return err
+	})
+}
+
+func (d *boltDriver) Ping(_ context.Context) error {
+	return d.db.View(func(*bolt.Tx) error { return nil })
+}
+func (d *boltDriver) Destroy(_ context.Context) error { return d.db.Close() }
+
+func (d *boltDriver) bucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	b := tx.Bucket(d.bucketName)
+	if b == nil {
+		return nil, fmt.Errorf("bucket %s not found, call Prepare first", d.bucketName)
+	}
+	return b, nil
+}
+
+func (d *boltDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (id uint64, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, err
+	}
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		b, err0 := d.bucket(tx)
+		if err0 != nil {
+			return err0
+		}
+		id = offsetOnCreate
+		if v := b.Get([]byte(domain)); v != nil {
+			id = binary.BigEndian.Uint64(v)
+		}
+		// 等待写事务期间ctx可能已结束，此时放弃提交
+		if err0 = ctx.Err(); err0 != nil {
+			return err0
+		}
+		return b.Put([]byte(domain), encodeBoltID(id+quantum))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (d *boltDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (returned bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return false, err
+	}
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		b, err0 := d.bucket(tx)
+		if err0 != nil {
+			return err0
+		}
+		if v := b.Get([]byte(domain)); v == nil || binary.BigEndian.Uint64(v) != max {
+			return nil
+		}
+		returned = true
+		return b.Put([]byte(domain), encodeBoltID(n))
+	})
+	return returned && err == nil, err
+}
+
+func encodeBoltID(id uint64) []byte {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, id)
+	return v
+}
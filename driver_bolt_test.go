@@ -0,0 +1,92 @@
+package siid
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	bolt "go.etcd.io/bbolt"
+)
+
+func openBoltDriver(path string) *boltDriver {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		panic(err)
+	}
+	driver := NewBoltDriver(db)
+	if err = driver.Prepare(context.Background()); err != nil {
+		panic(err)
+	}
+	return driver.(*boltDriver)
+}
+
+func Test_BoltDriverOffset(t *testing.T) {
+	driver := openBoltDriver(filepath.Join(t.TempDir(), "siid.db"))
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	Convey("bolt driver offset", t, func() {
+		current, err := driver.Renew(context.Background(), fmt.Sprintf("test_ts_%d", nowFunc().Unix()), 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain)
+	})
+}
+
+func Test_BoltDriverPersistence(t *testing.T) {
+	Convey("bolt driver persistence", t, func() {
+		path := filepath.Join(t.TempDir(), "siid.db")
+		driver := openBoltDriver(path)
+		current, err := driver.Renew(context.Background(), "persist", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain)
+		So(driver.Destroy(context.Background()), ShouldBeNil)
+
+		_, err = driver.Renew(context.Background(), "persist", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldEqual, bolt.ErrDatabaseNotOpen)
+
+		driver = openBoltDriver(path)
+		current, err = driver.Renew(context.Background(), "persist", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+1000)
+		So(driver.Destroy(context.Background()), ShouldBeNil)
+	})
+}
+
+func Test_BoltDriverConcurrentRenew(t *testing.T) {
+	driver := openBoltDriver(filepath.Join(t.TempDir(), "siid.db"))
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	Convey("bolt driver concurrent renew", t, func() {
+		const workers, quantum = 20, 100
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		seen := make(map[uint64]bool)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				current, err := driver.Renew(context.Background(), "concurrent", quantum, 0)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				seen[current] = true
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		So(len(seen), ShouldEqual, workers)
+		for i := 0; i < workers; i++ {
+			So(seen[uint64(i*quantum)], ShouldBeTrue)
+		}
+	})
+}
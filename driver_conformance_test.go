@@ -0,0 +1,108 @@
+package siid_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/drivertest"
+	"github.com/sandwich-go/siid/siidtest"
+	bolt "go.etcd.io/bbolt"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func TestRedisDriverConformance(t *testing.T) {
+	s := miniredis.RunT(t)
+	drivertest.Run(t, func(t *testing.T) siid.Driver {
+		client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return siid.NewRedisDriver(client)
+	})
+}
+
+func TestBoltDriverConformance(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "siid.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	// 同一个文件只能打开一次，所有实例共用一个Driver
+	driver := siid.NewBoltDriver(db)
+	drivertest.Run(t, func(*testing.T) siid.Driver { return driver })
+}
+
+func newMysqlDriver(t *testing.T) siid.Driver {
+	db, err := sql.Open("mysql", fmt.Sprintf("root:@tcp(%s)/mysql?charset=utf8", "127.0.0.1:3306"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.PingContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return siid.NewMysqlDriver(db)
+}
+
+func TestMysqlDriverConformance(t *testing.T) {
+	drivertest.Run(t, newMysqlDriver)
+}
+
+func TestMysqlAdminConformance(t *testing.T) {
+	drivertest.RunAdmin(t, newMysqlDriver)
+}
+
+func TestRemoteDriverConformance(t *testing.T) {
+	s := siid.NewRemoteServer(siidtest.NewDriver(), siid.NewConfig(siid.WithDevelopment(false)))
+	if err := s.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(siid.NewRemoteHandler(s))
+	t.Cleanup(func() {
+		srv.Close()
+		_ = s.Destroy(context.Background())
+	})
+	drivertest.Run(t, func(*testing.T) siid.Driver { return siid.NewRemoteDriver(srv.URL) })
+}
+
+func TestEtcdDriverConformance(t *testing.T) {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	lcurl, _ := url.Parse("http://127.0.0.1:0")
+	lpurl, _ := url.Parse("http://127.0.0.1:0")
+	cfg.LCUrls, cfg.LPUrls = []url.URL{*lcurl}, []url.URL{*lpurl}
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	<-e.Server.ReadyNotify()
+	drivertest.Run(t, func(t *testing.T) siid.Driver {
+		client, err0 := clientv3.New(clientv3.Config{Endpoints: []string{e.Clients[0].Addr().String()}, DialTimeout: 3 * time.Second})
+		if err0 != nil {
+			t.Fatal(err0)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+		return siid.NewEtcdDriver(client)
+	})
+}
+
+func TestFailoverDriverConformance(t *testing.T) {
+	// 所有实例共用相同的后端
+	primary, secondary := siidtest.NewDriver(), siidtest.NewDriver()
+	drivertest.Run(t, func(*testing.T) siid.Driver {
+		return siid.NewFailoverDriver(
+			siid.FailoverBackend{Name: "primary", Driver: primary, Ceiling: 1 << 62},
+			siid.FailoverBackend{Name: "secondary", Driver: secondary, Offset: 1 << 62})
+	})
+}
@@ -27,3 +27,13 @@ func (d *dummyDriver) Renew(_ context.Context, domain string, quantum, offset ui
 	d.mm[domain] += quantum
 	return val, nil
 }
+
+func (d *dummyDriver) ReturnSegment(_ context.Context, domain string, n, max uint64) (bool, error) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if val, ok := d.mm[domain]; !ok || val != max {
+		return false, nil
+	}
+	d.mm[domain] = n
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package siid
+
+import (
+	"context"
+	"strconv"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// 使用乐观事务完成renew，比较key的ModRevision，冲突时重新读取后重试
+// 新建domain时比较CreateRevision为0，保证只有一个实例能以offsetOnCreate创建
+// id以十进制字符串保存，便于使用etcdctl查看
+const defaultEtcdPrefix = "/siid/"
+
+type etcdDriver struct {
+	prefix string
+	client *clientv3.Client
+}
+
+// NewEtcdDriver 新建etcd driver，key为`/siid/<domain>`
+func NewEtcdDriver(client *clientv3.Client) Driver {
+	return NewEtcdDriverWithPrefix(client, defaultEtcdPrefix)
+}
+
+// NewEtcdDriverWithPrefix 新建etcd driver，key为`<prefix><domain>`
+func NewEtcdDriverWithPrefix(client *clientv3.Client, prefix string) Driver {
+	return &etcdDriver{client: client, prefix: prefix}
+}
+
+func (d *etcdDriver) key(domain string) string { return d.prefix + domain }
+
+func (d *etcdDriver) Prepare(ctx context.Context) error { return d.Ping(ctx) }
+func (d *etcdDriver) Ping(ctx context.Context) error {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	_, err := d.client.Get(ctx, d.prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	cancel()
+	return err
+}
+func (d *etcdDriver) Destroy(_ context.Context) error { return d.client.Close() }
+
+func (d *etcdDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	key := d.key(domain)
+	for {
+		resp, err := d.client.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		var curr uint64
+		var cmp clientv3.Cmp
+		if len(resp.Kvs) == 0 {
+			curr = offsetOnCreate
+			cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+		} else {
+			if curr, err = strconv.ParseUint(string(resp.Kvs[0].Value), 10, 64); err != nil {
+				return 0, err
+			}
+			cmp = clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)
+		}
+		txnResp, err := d.client.Txn(ctx).If(cmp).Then(clientv3.OpPut(key, strconv.FormatUint(curr+quantum, 10))).Commit()
+		if err != nil {
+			return 0, err
+		}
+		if txnResp.Succeeded {
+			return curr, nil
+		}
+		// 其他实例已修改，重试
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (d *etcdDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	key := d.key(domain)
+	resp, err := d.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(key), "=", strconv.FormatUint(max, 10))).
+		Then(clientv3.OpPut(key, strconv.FormatUint(n, 10))).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	return resp.Succeeded, nil
+}
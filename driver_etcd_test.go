@@ -0,0 +1,107 @@
+package siid
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startEmbedEtcd 启动内嵌的etcd，返回client地址
+func startEmbedEtcd(t *testing.T) string {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	lcurl, _ := url.Parse("http://127.0.0.1:0")
+	lpurl, _ := url.Parse("http://127.0.0.1:0")
+	cfg.LCUrls, cfg.LPUrls = []url.URL{*lcurl}, []url.URL{*lpurl}
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("embed etcd start timeout")
+	}
+	return e.Clients[0].Addr().String()
+}
+
+func getEtcdDriver(t *testing.T, addr string) *etcdDriver {
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{addr}, DialTimeout: 3 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	driver := NewEtcdDriver(client)
+	if err = driver.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = driver.Destroy(context.Background()) })
+	return driver.(*etcdDriver)
+}
+
+func Test_EtcdDriver(t *testing.T) {
+	addr := startEmbedEtcd(t)
+	Convey("etcd driver", t, func() {
+		driver := getEtcdDriver(t, addr)
+
+		Convey("offset", func() {
+			domain := fmt.Sprintf("test_ts_%d", nowFunc().UnixNano())
+			current, err := driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+			So(err, ShouldBeNil)
+			So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain)
+			current, err = driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+			So(err, ShouldBeNil)
+			So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+1000)
+		})
+
+		Convey("concurrent renew from many instances", func() {
+			const instances, workers, quantum = 3, 10, 100
+			domain := fmt.Sprintf("test_concurrent_%d", nowFunc().UnixNano())
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			seen := make(map[uint64]bool)
+			for i := 0; i < instances; i++ {
+				d := getEtcdDriver(t, addr)
+				for j := 0; j < workers; j++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						current, err := d.Renew(context.Background(), domain, quantum, 0)
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						mu.Lock()
+						seen[current] = true
+						mu.Unlock()
+					}()
+				}
+			}
+			wg.Wait()
+			So(len(seen), ShouldEqual, instances*workers)
+			for i := 0; i < instances*workers; i++ {
+				So(seen[uint64(i*quantum)], ShouldBeTrue)
+			}
+		})
+
+		Convey("return segment", func() {
+			domain := fmt.Sprintf("test_return_%d", nowFunc().UnixNano())
+			current, err := driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+			So(err, ShouldBeNil)
+			ok, err := driver.ReturnSegment(context.Background(), domain, current+10, current+1000)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			ok, err = driver.ReturnSegment(context.Background(), domain, current, current+1000)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,154 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandwich-go/logbus"
+)
+
+var ErrFailoverOutOfRange = errors.New("segment out of failover backend range")
+
+const (
+	defaultFailoverPrimaryTimeout    = 2 * time.Second
+	defaultFailoverRetryPrimaryAfter = 30 * time.Second
+)
+
+// FailoverBackend FailoverDriver的一个后端，负责所有domain中(Offset, Ceiling]区间内的id
+// 例如按高位划分：primary为(0, 1<<62]，secondary为(1<<62, 0]
+type FailoverBackend struct {
+	Name    string // 后端名称，用于日志及监控
+	Driver  Driver
+	Offset  uint64 // 新建domain时在offsetOnCreate基础上的偏移
+	Ceiling uint64 // id上限，0表示不限制
+}
+
+func (b *FailoverBackend) overlaps(o *FailoverBackend) bool {
+	return (b.Ceiling == 0 || o.Offset < b.Ceiling) && (o.Ceiling == 0 || b.Offset < o.Ceiling)
+}
+
+func (b *FailoverBackend) contains(n, max uint64) bool {
+	return n >= b.Offset && (b.Ceiling == 0 || max <= b.Ceiling)
+}
+
+func (b *FailoverBackend) renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	c, err := b.Driver.Renew(ctx, domain, quantum, b.Offset+offsetOnCreate)
+	if err == nil && !b.contains(c, c+quantum) {
+		err = fmt.Errorf("%w: backend %s, domain %s, segment (%d, %d]", ErrFailoverOutOfRange, b.Name, domain, c, c+quantum)
+	}
+	return c, err
+}
+
+// optionsVisitorSetter 需要读取Builder配置的Driver，由NewWithDriver设置
+type optionsVisitorSetter interface {
+	setOptionsVisitor(visitor OptionsVisitor)
+}
+
+type failoverDriver struct {
+	primary           FailoverBackend
+	secondary         FailoverBackend
+	primaryTimeout    time.Duration
+	retryPrimaryAfter time.Duration
+	failedAt          int64          // primary最近一次失败的UnixNano，0表示primary正常
+	visitor           OptionsVisitor // Builder的配置，用于判断是否开启监控，nil时按默认配置
+}
+
+func (f *failoverDriver) setOptionsVisitor(visitor OptionsVisitor) { f.visitor = visitor }
+
+func (f *failoverDriver) renew(ctx context.Context, b *FailoverBackend, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	c, err := b.renew(ctx, domain, quantum, offsetOnCreate)
+	f.renewReport(b, domain, err)
+	return c, err
+}
+
+// NewFailoverDriver 新建failover driver，primary不可用时改由secondary分配号段，primary恢复后切换回primary
+// 两个后端的id区间不能重叠，否则panic
+func NewFailoverDriver(primary, secondary FailoverBackend) Driver {
+	return NewFailoverDriverWithTimeout(primary, secondary, defaultFailoverPrimaryTimeout, defaultFailoverRetryPrimaryAfter)
+}
+
+// NewFailoverDriverWithTimeout 新建failover driver
+// primaryTimeout 单次向primary申请号段的超时，超时后改用secondary，0表示仅受ctx限制
+// retryPrimaryAfter 切换到secondary后，经过该时长再尝试primary
+func NewFailoverDriverWithTimeout(primary, secondary FailoverBackend, primaryTimeout, retryPrimaryAfter time.Duration) Driver {
+	if primary.Driver == nil || secondary.Driver == nil {
+		panicIfErr(errors.New("failover backend driver is nil"))
+	}
+	if primary.overlaps(&secondary) {
+		panicIfErr(fmt.Errorf("failover backend %s range (%d, %d] overlaps backend %s range (%d, %d]",
+			primary.Name, primary.Offset, primary.Ceiling, secondary.Name, secondary.Offset, secondary.Ceiling))
+	}
+	return &failoverDriver{primary: primary, secondary: secondary, primaryTimeout: primaryTimeout, retryPrimaryAfter: retryPrimaryAfter}
+}
+
+// Prepare 任一后端可用即可
+func (f *failoverDriver) Prepare(ctx context.Context) error {
+	errPrimary := f.primary.Driver.Prepare(ctx)
+	if errPrimary != nil {
+		atomic.StoreInt64(&f.failedAt, nowFunc().UnixNano())
+		logbus.Warn(w("prepare failover primary failed"), logbus.String("backend", f.primary.Name), logbus.ErrorField(errPrimary))
+	}
+	errSecondary := f.secondary.Driver.Prepare(ctx)
+	if errSecondary != nil {
+		logbus.Warn(w("prepare failover secondary failed"), logbus.String("backend", f.secondary.Name), logbus.ErrorField(errSecondary))
+		if errPrimary != nil {
+			return fmt.Errorf("prepare failover driver, primary: %v, secondary: %w", errPrimary, errSecondary)
+		}
+	}
+	return nil
+}
+
+func (f *failoverDriver) Destroy(ctx context.Context) error {
+	err := f.primary.Driver.Destroy(ctx)
+	if err0 := f.secondary.Driver.Destroy(ctx); err == nil {
+		err = err0
+	}
+	return err
+}
+
+func (f *failoverDriver) usePrimary() bool {
+	failedAt := atomic.LoadInt64(&f.failedAt)
+	return failedAt == 0 || nowFunc().Sub(time.Unix(0, failedAt)) >= f.retryPrimaryAfter
+}
+
+func (f *failoverDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	if f.usePrimary() {
+		primaryCtx, cancel := ctx, context.CancelFunc(func() {})
+		if f.primaryTimeout > 0 {
+			primaryCtx, cancel = context.WithTimeout(ctx, f.primaryTimeout)
+		}
+		c, err := f.renew(primaryCtx, &f.primary, domain, quantum, offsetOnCreate)
+		cancel()
+		if err == nil {
+			if atomic.SwapInt64(&f.failedAt, 0) != 0 {
+				logbus.Info(w("failback to primary"), logbus.String("backend", f.primary.Name), logbus.String("domain", domain))
+			}
+			return c, nil
+		}
+		if ctx.Err() != nil {
+			return 0, err
+		}
+		if atomic.SwapInt64(&f.failedAt, nowFunc().UnixNano()) == 0 {
+			logbus.Warn(w("failover to secondary"), logbus.String("backend", f.secondary.Name),
+				logbus.String("domain", domain), logbus.ErrorField(err))
+		}
+	}
+	return f.renew(ctx, &f.secondary, domain, quantum, offsetOnCreate)
+}
+
+// ReturnSegment 按号段所在区间归还给对应的后端
+func (f *failoverDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	for _, b := range []*FailoverBackend{&f.primary, &f.secondary} {
+		if !b.contains(n, max) {
+			continue
+		}
+		if returner, ok := b.Driver.(SegmentReturner); ok {
+			return returner.ReturnSegment(ctx, domain, n, max)
+		}
+		return false, nil
+	}
+	return false, nil
+}
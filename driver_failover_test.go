@@ -0,0 +1,87 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFailoverDriver(t *testing.T) {
+	Convey("failover driver", t, func() {
+		ctx := context.Background()
+		primary := &flakyDriver{Driver: getDummyDriver()}
+		secondary := getDummyDriver()
+		d := NewFailoverDriverWithTimeout(
+			FailoverBackend{Name: "primary", Driver: primary, Ceiling: 1 << 62},
+			FailoverBackend{Name: "secondary", Driver: secondary, Offset: 1 << 62},
+			time.Second, 20*time.Millisecond)
+		So(d.Prepare(ctx), ShouldBeNil)
+
+		c, err := d.Renew(ctx, "failover", 10, 100)
+		So(err, ShouldBeNil)
+		So(c, ShouldEqual, 100)
+
+		Convey("fallback to secondary and switch back", func() {
+			primary.fails = 1 << 30
+			c, err = d.Renew(ctx, "failover", 10, 100)
+			So(err, ShouldBeNil)
+			So(c, ShouldEqual, 1<<62+100)
+			calls := primary.calls
+			// 冷却期内不再尝试primary
+			c, err = d.Renew(ctx, "failover", 10, 100)
+			So(err, ShouldBeNil)
+			So(c, ShouldEqual, 1<<62+110)
+			So(primary.calls, ShouldEqual, calls)
+
+			primary.fails = 0
+			time.Sleep(30 * time.Millisecond)
+			c, err = d.Renew(ctx, "failover", 10, 100)
+			So(err, ShouldBeNil)
+			So(c, ShouldEqual, 110)
+
+			ok, err := d.(SegmentReturner).ReturnSegment(ctx, "failover", 110, 120)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(primary.Driver.(*dummyDriver).mm["failover"], ShouldEqual, 110)
+			ok, err = d.(SegmentReturner).ReturnSegment(ctx, "failover", 1<<62+110, 1<<62+120)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(secondary.mm["failover"], ShouldEqual, 1<<62+110)
+		})
+
+		Convey("segment out of range", func() {
+			_, err = NewFailoverDriver(
+				FailoverBackend{Name: "primary", Driver: primary, Ceiling: 200},
+				FailoverBackend{Name: "secondary", Driver: getDummyDriver(), Offset: 200, Ceiling: 210},
+			).Renew(ctx, "failover", 100, 100)
+			So(errors.Is(err, ErrFailoverOutOfRange), ShouldBeTrue)
+		})
+
+		Convey("monitor follows builder options", func() {
+			So(d.(*failoverDriver).visitor, ShouldBeNil)
+			b := NewWithDriver(d, NewConfig(WithEnableMonitor(false)))
+			So(d.(*failoverDriver).visitor.GetEnableMonitor(), ShouldBeFalse)
+			So(b.Prepare(ctx), ShouldBeNil)
+			e, err := b.Build("failover_monitor")
+			So(err, ShouldBeNil)
+			_, err = e.Next()
+			So(err, ShouldBeNil)
+		})
+
+		Convey("overlapped ranges", func() {
+			So(func() {
+				NewFailoverDriver(
+					FailoverBackend{Name: "primary", Driver: primary, Ceiling: 1 << 62},
+					FailoverBackend{Name: "secondary", Driver: secondary, Offset: 1 << 61})
+			}, ShouldPanic)
+			So(func() {
+				NewFailoverDriver(
+					FailoverBackend{Name: "primary", Driver: primary, Offset: 1 << 62},
+					FailoverBackend{Name: "secondary", Driver: secondary})
+			}, ShouldPanic)
+		})
+	})
+}
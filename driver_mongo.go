@@ -79,3 +79,93 @@ func (m *mongoDriver) renew(ctx context.Context, domain string, quantum uint64)
 	}
 	return doc.Current, nil
 }
+
+func (m *mongoDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	filter := bson.D{{Key: "_id", Value: domain}, {Key: "current", Value: max}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "current", Value: n}}}}
+	result, err := m.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+type mongoDomain struct {
+	Domain  string `bson:"_id"`
+	Current uint64 `bson:"current"`
+}
+
+func (m *mongoDriver) ListDomains(ctx context.Context) ([]DomainInfo, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	cursor, err := m.getCollection().Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []mongoDomain
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	domains := make([]DomainInfo, 0, len(docs))
+	for _, doc := range docs {
+		domains = append(domains, DomainInfo{Domain: doc.Domain, Current: doc.Current})
+	}
+	return domains, nil
+}
+
+func (m *mongoDriver) GetCurrent(ctx context.Context, domain string) (uint64, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	var doc mongoDomain
+	err := m.getCollection().FindOne(ctx, bson.D{{Key: "_id", Value: domain}}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return 0, domainNotFound(domain)
+	}
+	return doc.Current, err
+}
+
+func (m *mongoDriver) SetCurrent(ctx context.Context, domain string, current uint64, force bool) error {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	filter := bson.D{{Key: "_id", Value: domain}}
+	if !force {
+		filter = append(filter, bson.E{Key: "current", Value: bson.D{{Key: "$lte", Value: current}}})
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "current", Value: current}}}}
+	result, err := m.getCollection().UpdateOne(ctx, filter, update)
+	if err != nil || result.MatchedCount == 1 {
+		return err
+	}
+	if force {
+		return domainNotFound(domain)
+	}
+	return checkSetCurrent(ctx, m, domain, current)
+}
+
+func (m *mongoDriver) CreateDomain(ctx context.Context, domain string, current uint64) error {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	_, err := m.getCollection().InsertOne(ctx, bson.D{{Key: "_id", Value: domain}, {Key: "current", Value: current}})
+	if mongo.IsDuplicateKeyError(err) {
+		return domainExists(domain)
+	}
+	return err
+}
+
+func (m *mongoDriver) DeleteDomain(ctx context.Context, domain string) error {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	result, err := m.getCollection().DeleteOne(ctx, bson.D{{Key: "_id", Value: domain}})
+	if err == nil && result.DeletedCount == 0 {
+		err = domainNotFound(domain)
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+//go:build mongo
+// +build mongo
+
+package siid_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/drivertest"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func newMongoDriver(t *testing.T) siid.Driver {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://127.0.0.1:32797"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+	return siid.NewMongoDriver(client)
+}
+
+func TestMongoDriverConformance(t *testing.T) {
+	drivertest.Run(t, newMongoDriver)
+}
+
+func TestMongoAdminConformance(t *testing.T) {
+	drivertest.RunAdmin(t, newMongoDriver)
+}
@@ -26,12 +26,12 @@ func getMongoClient(addr string) *mongo.Client {
 	return client
 }
 
-func getMongolDriver(address string) *mysqlDriver {
+func getMongolDriver(address string) *mongoDriver {
 	driver := NewMongoDriver(getMongoClient(address))
 	if err := driver.Prepare(context.Background()); err != nil {
 		panic(err)
 	}
-	return driver.(*mysqlDriver)
+	return driver.(*mongoDriver)
 }
 
 func Test_MongoDriverOffset(t *testing.T) {
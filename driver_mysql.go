@@ -27,6 +27,14 @@ const (
 	sqlFmtSelForUp     = "SELECT id FROM %s.%s where domain='%s' FOR UPDATE"
 	sqlFmtAddID        = "UPDATE %s.%s SET id = id + %d where domain='%s'"
 	sqlFmtInsertDomain = "INSERT INTO %s.%s(domain,id) VALUES('%s',%d)"
+	sqlFmtReturnID     = "UPDATE %s.%s SET id = %d where domain='%s' AND id = %d"
+
+	sqlFmtListDomains     = "SELECT domain, id FROM %s.%s ORDER BY domain"
+	sqlFmtGetCurrent      = "SELECT id FROM %s.%s WHERE domain = ?"
+	sqlFmtSetCurrent      = "UPDATE %s.%s SET id = ? WHERE domain = ?"
+	sqlFmtSetCurrentAhead = "UPDATE %s.%s SET id = ? WHERE domain = ? AND id <= ?"
+	sqlFmtCreateDomain    = "INSERT IGNORE INTO %s.%s(domain,id) VALUES(?,?)"
+	sqlFmtDeleteDomain    = "DELETE FROM %s.%s WHERE domain = ?"
 )
 
 var emptyCancelFunc = context.CancelFunc(func() {})
@@ -138,3 +146,97 @@ func (d *mysqlDriver) renew(ctx context.Context, domain string, quantum uint64)
 	}
 	return id, nil
 }
+
+func (d *mysqlDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	result, err := d.db.ExecContext(ctx, fmt.Sprintf(sqlFmtReturnID, d.dbName, d.tableName, n, domain, max))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (d *mysqlDriver) ListDomains(ctx context.Context) ([]DomainInfo, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf(sqlFmtListDomains, d.dbName, d.tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var domains []DomainInfo
+	for rows.Next() {
+		var info DomainInfo
+		if err = rows.Scan(&info.Domain, &info.Current); err != nil {
+			return nil, err
+		}
+		domains = append(domains, info)
+	}
+	return domains, rows.Err()
+}
+
+func (d *mysqlDriver) GetCurrent(ctx context.Context, domain string) (uint64, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	var current uint64
+	err := d.db.QueryRowContext(ctx, fmt.Sprintf(sqlFmtGetCurrent, d.dbName, d.tableName), domain).Scan(&current)
+	if err == sql.ErrNoRows {
+		return 0, domainNotFound(domain)
+	}
+	return current, err
+}
+
+// exec 执行sql并返回影响的行数
+func (d *mysqlDriver) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	result, err := d.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (d *mysqlDriver) SetCurrent(ctx context.Context, domain string, current uint64, force bool) error {
+	var affected int64
+	var err error
+	if force {
+		affected, err = d.exec(ctx, fmt.Sprintf(sqlFmtSetCurrent, d.dbName, d.tableName), current, domain)
+	} else {
+		affected, err = d.exec(ctx, fmt.Sprintf(sqlFmtSetCurrentAhead, d.dbName, d.tableName), current, domain, current)
+	}
+	if err != nil || affected == 1 {
+		return err
+	}
+	// 未更新时可能是domain不存在、当前id更大或者当前id未变化
+	if force {
+		_, err = d.GetCurrent(ctx, domain)
+		return err
+	}
+	return checkSetCurrent(ctx, d, domain, current)
+}
+
+func (d *mysqlDriver) CreateDomain(ctx context.Context, domain string, current uint64) error {
+	affected, err := d.exec(ctx, fmt.Sprintf(sqlFmtCreateDomain, d.dbName, d.tableName), domain, current)
+	if err == nil && affected == 0 {
+		err = domainExists(domain)
+	}
+	return err
+}
+
+func (d *mysqlDriver) DeleteDomain(ctx context.Context, domain string) error {
+	affected, err := d.exec(ctx, fmt.Sprintf(sqlFmtDeleteDomain, d.dbName, d.tableName), domain)
+	if err == nil && affected == 0 {
+		err = domainNotFound(domain)
+	}
+	return err
+}
@@ -124,3 +124,26 @@ func Test_MysqlDriverShouldLockRow(t *testing.T) {
 	close(continueChan4)
 	time.Sleep(time.Duration(10) * time.Millisecond)
 }
+
+func Test_MysqlDriverReturnSegment(t *testing.T) {
+	driver := getMysqlDriver(mysqlAddress)
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	Convey("mysql driver return segment", t, func() {
+		domain := fmt.Sprintf("test_return_%d", nowFunc().UnixNano())
+		current, err := driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		ok, err := driver.ReturnSegment(context.Background(), domain, current+10, current+1000)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		ok, err = driver.ReturnSegment(context.Background(), domain, current, current+1000)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+		current, err = driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+10)
+	})
+}
@@ -0,0 +1,76 @@
+package siid
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// 使用INSERT ... ON CONFLICT DO UPDATE完成新建domain与renew，只需一次往返
+// https://www.postgresql.org/docs/current/sql-insert.html#SQL-ON-CONFLICT
+const (
+	sqlCreatePostgresSchemaIfNotExist = `CREATE SCHEMA IF NOT EXISTS %s`
+	sqlCreatePostgresTableIfNotExist  = `CREATE TABLE IF NOT EXISTS %s.%s (
+	domain varchar(30) NOT NULL PRIMARY KEY,
+	id bigint NOT NULL)`
+	sqlFmtPostgresRenew = `INSERT INTO %s.%s AS t (domain, id) VALUES ($1, $2::bigint + $3::bigint)
+	ON CONFLICT (domain) DO UPDATE SET id = t.id + $3::bigint RETURNING id - $3::bigint`
+	sqlFmtPostgresReturnID = `UPDATE %s.%s SET id = $1 WHERE domain = $2 AND id = $3`
+)
+
+type postgresDriver struct {
+	schemaName, tableName string
+	db                    *sql.DB
+}
+
+func NewPostgresDriver(client *sql.DB) Driver {
+	return NewPostgresDriverWithName(client, defaultName, defaultName)
+}
+
+func NewPostgresDriverWithName(client *sql.DB, schemaName, tableName string) Driver {
+	return &postgresDriver{db: client, schemaName: schemaName, tableName: tableName}
+}
+
+func (d *postgresDriver) Prepare(ctx context.Context) (err error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	if _, err = d.db.ExecContext(ctx, fmt.Sprintf(sqlCreatePostgresSchemaIfNotExist, d.schemaName)); err == nil {
+		_, err = d.db.ExecContext(ctx, fmt.Sprintf(sqlCreatePostgresTableIfNotExist, d.schemaName, d.tableName))
+	}
+	cancel()
+	return err
+}
+
+func (d *postgresDriver) Ping(ctx context.Context) error {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	err := d.db.PingContext(ctx)
+	cancel()
+	return err
+}
+func (d *postgresDriver) Destroy(_ context.Context) error { return d.db.Close() }
+func (d *postgresDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	var curr int64
+	err := d.db.QueryRowContext(ctx, fmt.Sprintf(sqlFmtPostgresRenew, d.schemaName, d.tableName),
+		domain, int64(offsetOnCreate), int64(quantum)).Scan(&curr)
+	cancel()
+	return uint64(curr), err
+}
+
+func (d *postgresDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	result, err := d.db.ExecContext(ctx, fmt.Sprintf(sqlFmtPostgresReturnID, d.schemaName, d.tableName),
+		int64(n), domain, int64(max))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
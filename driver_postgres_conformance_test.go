@@ -0,0 +1,23 @@
+package siid_test
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/drivertest"
+)
+
+// embedded postgres按需启动，启动失败时跳过
+func TestPostgresDriverConformance(t *testing.T) {
+	dsn := siid.PostgresTestDSN(t)
+	drivertest.Run(t, func(t *testing.T) siid.Driver {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return siid.NewPostgresDriver(db)
+	})
+}
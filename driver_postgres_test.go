@@ -0,0 +1,143 @@
+package siid
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const postgresPort = 54329
+
+var (
+	postgresDSN      = fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=postgres sslmode=disable", postgresPort)
+	postgresOnce     sync.Once
+	postgresStartErr error
+)
+
+// startPostgres 首次使用时启动embedded postgres，包内所有测试结束后停止
+// 启动失败（如无法下载二进制）时跳过postgres相关测试
+func startPostgres(t *testing.T) {
+	postgresOnce.Do(func() {
+		pg := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().Port(postgresPort))
+		if postgresStartErr = pg.Start(); postgresStartErr != nil {
+			return
+		}
+		addPackageCleanup(func() {
+			if err := pg.Stop(); err != nil {
+				fmt.Fprintf(os.Stderr, "stop embedded postgres: %v\n", err)
+			}
+		})
+	})
+	if postgresStartErr != nil {
+		t.Skip("embedded postgres unavailable:", postgresStartErr)
+	}
+}
+
+// PostgresTestDSN 按需启动embedded postgres并返回连接串，供siid_test包中的测试使用
+func PostgresTestDSN(t *testing.T) string {
+	startPostgres(t)
+	return postgresDSN
+}
+
+func getPostgresDriver(t *testing.T) *postgresDriver {
+	startPostgres(t)
+	if db, err := sql.Open("postgres", postgresDSN); err != nil {
+		panic(err)
+	} else {
+		if err = db.Ping(); err != nil {
+			panic(err)
+		}
+		driver := NewPostgresDriver(db)
+		if err = driver.Prepare(context.Background()); err != nil {
+			panic(err)
+		}
+		// Prepare可重复调用
+		if err = driver.Prepare(context.Background()); err != nil {
+			panic(err)
+		}
+		return driver.(*postgresDriver)
+	}
+}
+
+func Test_PostgresDriverOffset(t *testing.T) {
+	driver := getPostgresDriver(t)
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	Convey("postgres driver offset", t, func() {
+		domain := fmt.Sprintf("test_ts_%d", nowFunc().Unix())
+		current, err := driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain)
+		current, err = driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+1000)
+	})
+}
+
+func Test_PostgresDriverConcurrentRenew(t *testing.T) {
+	driver := getPostgresDriver(t)
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	Convey("postgres driver concurrent renew", t, func() {
+		const workers, quantum = 20, 100
+		domain := fmt.Sprintf("test_concurrent_%d", nowFunc().Unix())
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		seen := make(map[uint64]bool)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				current, err := driver.Renew(context.Background(), domain, quantum, 0)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				seen[current] = true
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		So(len(seen), ShouldEqual, workers)
+		for i := 0; i < workers; i++ {
+			So(seen[uint64(i*quantum)], ShouldBeTrue)
+		}
+	})
+}
+
+func Test_PostgresDriverReturnSegment(t *testing.T) {
+	driver := getPostgresDriver(t)
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	Convey("postgres driver return segment", t, func() {
+		domain := fmt.Sprintf("test_return_%d", nowFunc().Unix())
+		current, err := driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		ok, err := driver.ReturnSegment(context.Background(), domain, current+10, current+1000)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		ok, err = driver.ReturnSegment(context.Background(), domain, current, current+1000)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+		current, err = driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+10)
+	})
+}
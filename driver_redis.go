@@ -0,0 +1,94 @@
+package siid
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+)
+
+// redis lua中的数字为double，超过2^53的整数会丢失精度
+const redisMaxSafeInteger = uint64(1) << 53
+
+// KEYS[1] domain对应的key
+// ARGV[1] quantum
+// ARGV[2] offsetOnCreate
+// ARGV[3] redisMaxSafeInteger
+// 返回renew之前的当前id，renew后将超过安全整数时不写入并返回错误
+var redisRenewScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	current = ARGV[2]
+end
+if tonumber(current) + tonumber(ARGV[1]) >= tonumber(ARGV[3]) then
+	return redis.error_reply('current id ' .. current .. ' exceeds redis lua safe integer')
+end
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return redis.call('INCRBY', KEYS[1], ARGV[1]) - tonumber(ARGV[1])
+`)
+
+// KEYS[1] domain对应的key
+// ARGV[1] n
+// ARGV[2] max
+// 当前id仍为max时设置回n，返回是否设置成功
+var redisReturnScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[2] then
+	redis.call('SET', KEYS[1], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+type redisDriver struct {
+	prefix string
+	client redis.UniversalClient
+}
+
+// NewRedisDriver 新建redis driver，key为`siid:<domain>`
+func NewRedisDriver(client redis.UniversalClient) Driver {
+	return NewRedisDriverWithName(client, defaultName, false)
+}
+
+// NewRedisDriverWithName 新建redis driver，key为`<prefix>:<domain>`
+// hashTag 为true时key为`{<prefix>}:<domain>`，Redis Cluster下所有domain位于同一slot
+func NewRedisDriverWithName(client redis.UniversalClient, prefix string, hashTag bool) Driver {
+	if hashTag {
+		prefix = fmt.Sprintf("{%s}", prefix)
+	}
+	return &redisDriver{client: client, prefix: prefix}
+}
+
+func (r *redisDriver) key(domain string) string { return fmt.Sprintf("%s:%s", r.prefix, domain) }
+
+func (r *redisDriver) Prepare(ctx context.Context) error { return r.Ping(ctx) }
+func (r *redisDriver) Ping(ctx context.Context) error {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	err := r.client.Ping(ctx).Err()
+	cancel()
+	return err
+}
+func (r *redisDriver) Destroy(_ context.Context) error { return r.client.Close() }
+
+func (r *redisDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	if quantum+offsetOnCreate >= redisMaxSafeInteger {
+		return 0, fmt.Errorf("quantum %d with offset %d exceeds redis lua safe integer", quantum, offsetOnCreate)
+	}
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	curr, err := redisRenewScript.Run(ctx, r.client, []string{r.key(domain)}, quantum, offsetOnCreate, redisMaxSafeInteger).Uint64()
+	cancel()
+	if err != nil {
+		return 0, fmt.Errorf("domain %s: %w", domain, err)
+	}
+	return curr, nil
+}
+
+func (r *redisDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	returned, err := redisReturnScript.Run(ctx, r.client, []string{r.key(domain)}, n, max).Int()
+	cancel()
+	return returned == 1, err
+}
@@ -0,0 +1,118 @@
+package siid
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func getRedisDriver(t *testing.T, prefix string, hashTag bool) (*redisDriver, *miniredis.Miniredis) {
+	s := miniredis.RunT(t)
+	driver := NewRedisDriverWithName(redis.NewClient(&redis.Options{Addr: s.Addr()}), prefix, hashTag)
+	if err := driver.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err0 := driver.Destroy(context.Background()); err0 != nil {
+			t.Error(err0)
+		}
+	})
+	return driver.(*redisDriver), s
+}
+
+func Test_RedisDriverOffset(t *testing.T) {
+	driver, _ := getRedisDriver(t, defaultName, false)
+	Convey("redis driver offset", t, func() {
+		domain := fmt.Sprintf("test_ts_%d", nowFunc().Unix())
+		current, err := driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain)
+		current, err = driver.Renew(context.Background(), domain, 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+1000)
+	})
+}
+
+func Test_RedisDriverKey(t *testing.T) {
+	Convey("redis driver key", t, func() {
+		driver, s := getRedisDriver(t, "ids", false)
+		_, err := driver.Renew(context.Background(), "player", 10, 0)
+		So(err, ShouldBeNil)
+		So(s.Exists("ids:player"), ShouldBeTrue)
+
+		driver, s = getRedisDriver(t, "ids", true)
+		_, err = driver.Renew(context.Background(), "player", 10, 0)
+		So(err, ShouldBeNil)
+		So(s.Exists("{ids}:player"), ShouldBeTrue)
+	})
+}
+
+func Test_RedisDriverConcurrentRenew(t *testing.T) {
+	driver, _ := getRedisDriver(t, defaultName, false)
+	Convey("redis driver concurrent renew", t, func() {
+		const workers, quantum = 20, 100
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		seen := make(map[uint64]bool)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				current, err := driver.Renew(context.Background(), "concurrent", quantum, 0)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				seen[current] = true
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		So(len(seen), ShouldEqual, workers)
+		for i := 0; i < workers; i++ {
+			So(seen[uint64(i*quantum)], ShouldBeTrue)
+		}
+	})
+}
+
+func Test_RedisDriverReturnSegment(t *testing.T) {
+	driver, _ := getRedisDriver(t, defaultName, false)
+	Convey("redis driver return segment", t, func() {
+		current, err := driver.Renew(context.Background(), "return", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		ok, err := driver.ReturnSegment(context.Background(), "return", current+10, current+1000)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+		ok, err = driver.ReturnSegment(context.Background(), "return", current, current+1000)
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeFalse)
+		current, err = driver.Renew(context.Background(), "return", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain+10)
+	})
+}
+
+func Test_RedisDriverSafeInteger(t *testing.T) {
+	driver, s := getRedisDriver(t, defaultName, false)
+	Convey("redis driver safe integer", t, func() {
+		key := driver.key("safe")
+		limit := redisMaxSafeInteger - 100
+		So(s.Set(key, fmt.Sprint(limit)), ShouldBeNil)
+		current, err := driver.Renew(context.Background(), "safe", 10, 0)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, limit)
+
+		// 超过安全整数时不写入
+		_, err = driver.Renew(context.Background(), "safe", 90, 0)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "safe integer")
+		v, _ := s.Get(key)
+		So(v, ShouldEqual, fmt.Sprint(limit+10))
+	})
+}
@@ -0,0 +1,78 @@
+package siid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// remoteDriver 通过HTTP/JSON向siid服务申请号段，号段仍在本进程内缓存，只有siid服务访问数据库
+type remoteDriver struct {
+	addr   string
+	client *http.Client
+	closed int32
+}
+
+// NewRemoteDriver 新建remote driver，addr为siid服务的HTTP地址，例如`http://127.0.0.1:8080`
+func NewRemoteDriver(addr string) Driver {
+	return NewRemoteDriverWithClient(addr, &http.Client{})
+}
+
+// NewRemoteDriverWithClient 新建remote driver，使用指定的http.Client
+func NewRemoteDriverWithClient(addr string, client *http.Client) Driver {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return &remoteDriver{addr: strings.TrimRight(addr, "/"), client: client}
+}
+
+func (d *remoteDriver) Prepare(ctx context.Context) error { return d.Ping(ctx) }
+func (d *remoteDriver) Ping(ctx context.Context) error {
+	return d.call(ctx, RemotePathPing, struct{}{}, &struct{}{})
+}
+func (d *remoteDriver) Destroy(_ context.Context) error {
+	atomic.StoreInt32(&d.closed, 1)
+	d.client.CloseIdleConnections()
+	return nil
+}
+
+func (d *remoteDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	var resp RemoteRenewResponse
+	err := d.call(ctx, RemotePathRenew, &RemoteRenewRequest{Domain: domain, Quantum: quantum, OffsetOnCreate: offsetOnCreate}, &resp)
+	return resp.Current, err
+}
+
+func (d *remoteDriver) call(ctx context.Context, path string, req, resp interface{}) error {
+	if atomic.LoadInt32(&d.closed) == 1 {
+		return ErrorDriverHasClosed
+	}
+	var cancel context.CancelFunc
+	ctx, cancel = wrapperContext(ctx)
+	defer cancel()
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := d.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode != http.StatusOK {
+		var remoteErr RemoteError
+		if err = json.NewDecoder(httpResp.Body).Decode(&remoteErr); err != nil || remoteErr.Error == "" {
+			return fmt.Errorf("remote %s: %s", path, httpResp.Status)
+		}
+		return fmt.Errorf("remote %s: %s", path, remoteErr.Error)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
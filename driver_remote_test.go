@@ -0,0 +1,108 @@
+package siid
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func getRemoteServer(t *testing.T) *httptest.Server {
+	s := NewRemoteServer(getDummyDriver(), NewConfig(
+		WithOffsetWhenAutoCreateDomain(defaultOffsetWhenAutoCreateDomain),
+		WithDevelopment(false)))
+	if err := s.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewRemoteHandler(s))
+	t.Cleanup(func() {
+		srv.Close()
+		_ = s.Destroy(context.Background())
+	})
+	return srv
+}
+
+func Test_RemoteDriverOffset(t *testing.T) {
+	srv := getRemoteServer(t)
+	driver := NewRemoteDriver(srv.URL)
+	Convey("remote driver offset", t, func() {
+		So(driver.Prepare(context.Background()), ShouldBeNil)
+		current, err := driver.Renew(context.Background(), "remote", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldBeNil)
+		So(current, ShouldEqual, defaultOffsetWhenAutoCreateDomain)
+
+		_, err = driver.Renew(context.Background(), "", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, ErrRemoteEmptyDomain.Error())
+
+		// 段长须在[1, MaxQuantum]内
+		for _, quantum := range []uint64{0, newDefaultOptions().MaxQuantum + 1} {
+			_, err = driver.Renew(context.Background(), "remote", quantum, defaultOffsetWhenAutoCreateDomain)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, ErrRemoteInvalidQuantum.Error())
+		}
+
+		So(driver.Destroy(context.Background()), ShouldBeNil)
+		_, err = driver.Renew(context.Background(), "remote", 1000, defaultOffsetWhenAutoCreateDomain)
+		So(err, ShouldEqual, ErrorDriverHasClosed)
+	})
+}
+
+func Test_RemoteBuilder(t *testing.T) {
+	srv := getRemoteServer(t)
+	Convey("builder over remote driver", t, func() {
+		b := NewWithDriver(NewRemoteDriver(strings.TrimPrefix(srv.URL, "http://")), NewConfig(WithDevelopment(false)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, err := b.Build("remote_builder")
+		So(err, ShouldBeNil)
+		id, err := e.Next()
+		So(err, ShouldBeNil)
+		So(id, ShouldEqual, defaultOffsetWhenAutoCreateDomain+1)
+		So(b.Destroy(context.Background()), ShouldBeNil)
+	})
+}
+
+func Test_RemoteHandlerNext(t *testing.T) {
+	srv := getRemoteServer(t)
+	post := func(path, body string) (int, string) {
+		resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+		So(err, ShouldBeNil)
+		defer func() { _ = resp.Body.Close() }()
+		data, err := ioutil.ReadAll(resp.Body)
+		So(err, ShouldBeNil)
+		return resp.StatusCode, strings.TrimSpace(string(data))
+	}
+	Convey("remote handler next", t, func() {
+		code, body := post(RemotePathNext, `{"domain":"next"}`)
+		So(code, ShouldEqual, http.StatusOK)
+		So(body, ShouldEqual, `{"first":"30000001","last":"30000001"}`)
+
+		code, body = post(RemotePathNext, `{"domain":"next","n":"10"}`)
+		So(code, ShouldEqual, http.StatusOK)
+		So(body, ShouldEqual, `{"first":"30000002","last":"30000011"}`)
+
+		code, _ = post(RemotePathNext, `{"domain":""}`)
+		So(code, ShouldEqual, http.StatusBadRequest)
+
+		code, _ = post(RemotePathNext, `not json`)
+		So(code, ShouldEqual, http.StatusBadRequest)
+	})
+}
+
+func Test_RemoteServerRenewMiddlewares(t *testing.T) {
+	Convey("remote server renew through middlewares", t, func() {
+		driver := &flakyDriver{Driver: getDummyDriver(), fails: 1}
+		s := NewRemoteServer(driver, NewConfig(WithDevelopment(false)))
+		So(s.Prepare(context.Background()), ShouldBeNil)
+		// 内置的重试中间件生效
+		resp, err := s.Renew(context.Background(), &RemoteRenewRequest{Domain: "middleware", Quantum: 10})
+		So(err, ShouldBeNil)
+		So(resp.Current, ShouldEqual, 0)
+		So(driver.calls, ShouldEqual, 2)
+		So(s.Destroy(context.Background()), ShouldBeNil)
+	})
+}
@@ -0,0 +1,300 @@
+// Package drivertest 提供siid.Driver的一致性测试
+//
+//	func TestMyDriver(t *testing.T) {
+//		drivertest.Run(t, func(t *testing.T) siid.Driver {
+//			return NewMyDriver(...)
+//		})
+//	}
+//
+// 实现了siid.AdminDriver的Driver可以使用RunAdmin测试管理接口
+package drivertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sandwich-go/siid"
+)
+
+// Factory 返回一个未Prepare的Driver实例，多次调用返回的实例须共享同一个存储，模拟多个进程
+// 若存储不支持多个实例同时打开（例如本地文件），可以每次返回同一个实例
+// Run只在最后的Destroy用例中调用Destroy，其余资源由Factory通过t.Cleanup释放
+type Factory func(t *testing.T) siid.Driver
+
+const (
+	instances  = 4  // 并发用例中Driver实例数
+	goroutines = 8  // 每个实例的并发数
+	renews     = 10 // 每个goroutine的renew次数
+)
+
+var seq uint64
+
+// uniqueDomain 返回唯一的domain，存储可能保留着之前运行的数据
+func uniqueDomain(name string) string {
+	return fmt.Sprintf("dt_%s_%d_%d", name, time.Now().Unix(), atomic.AddUint64(&seq, 1))
+}
+
+func prepare(t *testing.T, factory Factory) siid.Driver {
+	t.Helper()
+	d := factory(t)
+	if err := d.Prepare(context.Background()); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	return d
+}
+
+func renew(t *testing.T, d siid.Driver, domain string, quantum, offset uint64) uint64 {
+	t.Helper()
+	c, err := d.Renew(context.Background(), domain, quantum, offset)
+	if err != nil {
+		t.Fatalf("renew domain %s: %v", domain, err)
+	}
+	return c
+}
+
+// Run 运行Driver的一致性测试
+func Run(t *testing.T, factory Factory) {
+	t.Run("PrepareIdempotent", func(t *testing.T) { testPrepareIdempotent(t, factory) })
+	t.Run("OffsetOnCreate", func(t *testing.T) { testOffsetOnCreate(t, factory) })
+	t.Run("Sequential", func(t *testing.T) { testSequential(t, factory) })
+	t.Run("DomainIsolation", func(t *testing.T) { testDomainIsolation(t, factory) })
+	t.Run("ConcurrentRenew", func(t *testing.T) { testConcurrentRenew(t, factory) })
+	t.Run("ContextCanceled", func(t *testing.T) { testContextCanceled(t, factory) })
+	t.Run("RenewAfterDestroy", func(t *testing.T) { testRenewAfterDestroy(t, factory) })
+}
+
+func testPrepareIdempotent(t *testing.T, factory Factory) {
+	d := prepare(t, factory)
+	if err := d.Prepare(context.Background()); err != nil {
+		t.Fatalf("prepare again: %v", err)
+	}
+	domain := uniqueDomain("prepare")
+	renew(t, d, domain, 10, 0)
+	if err := prepare(t, factory).Prepare(context.Background()); err != nil {
+		t.Fatalf("prepare another instance: %v", err)
+	}
+	if c := renew(t, d, domain, 10, 0); c != 10 {
+		t.Fatalf("prepare should keep existing domain, got current %d, want 10", c)
+	}
+}
+
+func testOffsetOnCreate(t *testing.T, factory Factory) {
+	d := prepare(t, factory)
+	domain := uniqueDomain("offset")
+	if c := renew(t, d, domain, 100, 30000000); c != 30000000 {
+		t.Fatalf("first renew got current %d, want offsetOnCreate 30000000", c)
+	}
+	// offsetOnCreate只在新建domain时生效
+	if c := renew(t, d, domain, 100, 50000000); c != 30000100 {
+		t.Fatalf("second renew got current %d, want 30000100", c)
+	}
+	if c := renew(t, d, uniqueDomain("offset_zero"), 100, 0); c != 0 {
+		t.Fatalf("first renew with zero offset got current %d, want 0", c)
+	}
+}
+
+func testSequential(t *testing.T, factory Factory) {
+	d := prepare(t, factory)
+	domain := uniqueDomain("sequential")
+	var want uint64 = 1000
+	for _, quantum := range []uint64{1, 10, 30, 3000, 7} {
+		if c := renew(t, d, domain, quantum, 1000); c != want {
+			t.Fatalf("renew quantum %d got current %d, want %d", quantum, c, want)
+		}
+		want += quantum
+	}
+}
+
+func testDomainIsolation(t *testing.T, factory Factory) {
+	d := prepare(t, factory)
+	a, b := uniqueDomain("isolation_a"), uniqueDomain("isolation_b")
+	renew(t, d, a, 100, 0)
+	renew(t, d, a, 100, 0)
+	if c := renew(t, d, b, 100, 0); c != 0 {
+		t.Fatalf("domain %s got current %d, want 0", b, c)
+	}
+	if c := renew(t, d, a, 100, 0); c != 200 {
+		t.Fatalf("domain %s got current %d, want 200", a, c)
+	}
+}
+
+func testConcurrentRenew(t *testing.T, factory Factory) {
+	const quantum, offset = 7, 100
+	domain := uniqueDomain("concurrent")
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var currents []uint64
+	for i := 0; i < instances; i++ {
+		d := prepare(t, factory)
+		for j := 0; j < goroutines; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < renews; k++ {
+					c, err := d.Renew(context.Background(), domain, quantum, offset)
+					if err != nil {
+						t.Errorf("renew domain %s: %v", domain, err)
+						return
+					}
+					mu.Lock()
+					currents = append(currents, c)
+					mu.Unlock()
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+	sort.Slice(currents, func(i, j int) bool { return currents[i] < currents[j] })
+	if currents[0] != offset {
+		t.Fatalf("lowest current %d, want offsetOnCreate %d", currents[0], offset)
+	}
+	for i := 1; i < len(currents); i++ {
+		if currents[i]-currents[i-1] < quantum {
+			t.Fatalf("segments overlap: [%d, %d) and [%d, %d)",
+				currents[i-1], currents[i-1]+quantum, currents[i], currents[i]+quantum)
+		}
+	}
+}
+
+func testContextCanceled(t *testing.T, factory Factory) {
+	d := prepare(t, factory)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := d.Renew(ctx, uniqueDomain("canceled"), 10, 0); err == nil {
+		t.Fatal("renew with canceled context should fail")
+	}
+}
+
+func testRenewAfterDestroy(t *testing.T, factory Factory) {
+	d := prepare(t, factory)
+	domain := uniqueDomain("destroy")
+	renew(t, d, domain, 10, 0)
+	if err := d.Destroy(context.Background()); err != nil {
+		t.Fatalf("destroy: %v", err)
+	}
+	if _, err := d.Renew(context.Background(), domain, 10, 0); err == nil {
+		t.Fatal("renew after destroy should fail")
+	}
+}
+
+func prepareAdmin(t *testing.T, factory Factory) (siid.Driver, siid.AdminDriver) {
+	t.Helper()
+	d := prepare(t, factory)
+	admin, ok := d.(siid.AdminDriver)
+	if !ok {
+		t.Fatalf("%T does not implement siid.AdminDriver", d)
+	}
+	return d, admin
+}
+
+func expectErr(t *testing.T, err, target error, format string, args ...interface{}) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("%s: got error %v, want %v", fmt.Sprintf(format, args...), err, target)
+	}
+}
+
+// RunAdmin 运行AdminDriver的一致性测试
+func RunAdmin(t *testing.T, factory Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) { testAdminCreateAndGet(t, factory) })
+	t.Run("SetCurrent", func(t *testing.T) { testAdminSetCurrent(t, factory) })
+	t.Run("ListDomains", func(t *testing.T) { testAdminListDomains(t, factory) })
+	t.Run("DeleteDomain", func(t *testing.T) { testAdminDeleteDomain(t, factory) })
+}
+
+func testAdminCreateAndGet(t *testing.T, factory Factory) {
+	d, admin := prepareAdmin(t, factory)
+	ctx := context.Background()
+	domain := uniqueDomain("admin_create")
+	_, err := admin.GetCurrent(ctx, domain)
+	expectErr(t, err, siid.ErrDomainNotFound, "get missing domain %s", domain)
+	if err = admin.CreateDomain(ctx, domain, 500); err != nil {
+		t.Fatalf("create domain %s: %v", domain, err)
+	}
+	expectErr(t, admin.CreateDomain(ctx, domain, 600), siid.ErrDomainExists, "create domain %s again", domain)
+	if c, err := admin.GetCurrent(ctx, domain); err != nil || c != 500 {
+		t.Fatalf("get domain %s got current %d, %v, want 500", domain, c, err)
+	}
+	// 已创建的domain不受offsetOnCreate影响
+	if c := renew(t, d, domain, 10, 30000000); c != 500 {
+		t.Fatalf("renew created domain %s got current %d, want 500", domain, c)
+	}
+	if c, _ := admin.GetCurrent(ctx, domain); c != 510 {
+		t.Fatalf("get domain %s after renew got current %d, want 510", domain, c)
+	}
+}
+
+func testAdminSetCurrent(t *testing.T, factory Factory) {
+	d, admin := prepareAdmin(t, factory)
+	ctx := context.Background()
+	domain := uniqueDomain("admin_set")
+	expectErr(t, admin.SetCurrent(ctx, domain, 100, false), siid.ErrDomainNotFound, "set missing domain %s", domain)
+	expectErr(t, admin.SetCurrent(ctx, domain, 100, true), siid.ErrDomainNotFound, "force set missing domain %s", domain)
+	renew(t, d, domain, 10, 1000)
+	for _, current := range []uint64{2000, 2000} {
+		if err := admin.SetCurrent(ctx, domain, current, false); err != nil {
+			t.Fatalf("set domain %s current %d: %v", domain, current, err)
+		}
+	}
+	expectErr(t, admin.SetCurrent(ctx, domain, 1500, false), siid.ErrCurrentBackwards, "set domain %s backwards", domain)
+	if c, _ := admin.GetCurrent(ctx, domain); c != 2000 {
+		t.Fatalf("backwards set should not change domain %s, got current %d", domain, c)
+	}
+	if err := admin.SetCurrent(ctx, domain, 1500, true); err != nil {
+		t.Fatalf("force set domain %s backwards: %v", domain, err)
+	}
+	if c := renew(t, d, domain, 10, 0); c != 1500 {
+		t.Fatalf("renew domain %s after set got current %d, want 1500", domain, c)
+	}
+}
+
+func testAdminListDomains(t *testing.T, factory Factory) {
+	_, admin := prepareAdmin(t, factory)
+	ctx := context.Background()
+	prefix := uniqueDomain("admin_list")
+	want := []siid.DomainInfo{{Domain: prefix + "_a", Current: 1}, {Domain: prefix + "_b", Current: 2}, {Domain: prefix + "_c", Current: 3}}
+	for _, i := range []int{2, 0, 1} {
+		if err := admin.CreateDomain(ctx, want[i].Domain, want[i].Current); err != nil {
+			t.Fatalf("create domain %s: %v", want[i].Domain, err)
+		}
+	}
+	domains, err := admin.ListDomains(ctx)
+	if err != nil {
+		t.Fatalf("list domains: %v", err)
+	}
+	// 存储中可能存在其他用例的domain，且不同存储的排序规则不同，只检查本用例的domain
+	var got []siid.DomainInfo
+	for _, info := range domains {
+		if len(info.Domain) > len(prefix) && info.Domain[:len(prefix)] == prefix {
+			got = append(got, info)
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("list domains got %v, want %v", got, want)
+	}
+}
+
+func testAdminDeleteDomain(t *testing.T, factory Factory) {
+	d, admin := prepareAdmin(t, factory)
+	ctx := context.Background()
+	domain := uniqueDomain("admin_delete")
+	expectErr(t, admin.DeleteDomain(ctx, domain), siid.ErrDomainNotFound, "delete missing domain %s", domain)
+	renew(t, d, domain, 10, 1000)
+	if err := admin.DeleteDomain(ctx, domain); err != nil {
+		t.Fatalf("delete domain %s: %v", domain, err)
+	}
+	_, err := admin.GetCurrent(ctx, domain)
+	expectErr(t, err, siid.ErrDomainNotFound, "get deleted domain %s", domain)
+	if c := renew(t, d, domain, 10, 2000); c != 2000 {
+		t.Fatalf("renew deleted domain %s got current %d, want offsetOnCreate 2000", domain, c)
+	}
+}
@@ -0,0 +1,18 @@
+package drivertest
+
+import (
+	"testing"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/siidtest"
+)
+
+func TestRunWithMemoryDriver(t *testing.T) {
+	d := siidtest.NewDriver()
+	Run(t, func(*testing.T) siid.Driver { return d })
+}
+
+func TestRunAdminWithMemoryDriver(t *testing.T) {
+	d := siidtest.NewDriver()
+	RunAdmin(t, func(*testing.T) siid.Driver { return d })
+}
@@ -0,0 +1,153 @@
+package siid
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEncodeOverflow    = errors.New("id overflows encoder bits")
+	ErrUnknownKeyVersion = errors.New("unknown encoder key version")
+)
+
+const (
+	feistelRounds      = 8
+	encoderMinKeyBytes = 16
+)
+
+// EncoderKey 混淆密钥，Version写入混淆后id的高位，Decode据此选择密钥
+type EncoderKey struct {
+	Version uint64
+	Key     []byte
+}
+
+// Encoder 基于Feistel网络的可逆置换，将连续的id映射为看似随机的id，避免泄露id的增长速度
+// 混淆后的id为 Version<<Bits | permute(id)，共Bits+VersionBits位
+// 轮换密钥时追加新版本的密钥，新id使用最后一个密钥，已发出的id仍可用旧密钥Decode
+type Encoder struct {
+	bits        uint
+	versionBits uint
+	keys        map[uint64][]byte
+	current     uint64
+}
+
+// NewEncoder 新建Encoder
+// bits 置换的位宽，取值[2, 64-versionBits]，id须小于2^bits
+// versionBits 密钥版本的位宽
+// keys 各版本的密钥，最后一个为当前使用的密钥
+func NewEncoder(bits, versionBits uint, keys ...EncoderKey) (*Encoder, error) {
+	if bits < 2 || bits+versionBits > 64 {
+		return nil, fmt.Errorf("invalid encoder bits %d with version bits %d", bits, versionBits)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("encoder key is empty")
+	}
+	enc := &Encoder{bits: bits, versionBits: versionBits, keys: make(map[uint64][]byte, len(keys))}
+	for _, k := range keys {
+		if versionBits < 64 && k.Version>>versionBits != 0 {
+			return nil, fmt.Errorf("encoder key version %d overflows version bits %d", k.Version, versionBits)
+		}
+		if len(k.Key) < encoderMinKeyBytes {
+			return nil, fmt.Errorf("encoder key version %d shorter than %d bytes", k.Version, encoderMinKeyBytes)
+		}
+		if _, dup := enc.keys[k.Version]; dup {
+			return nil, fmt.Errorf("duplicate encoder key version %d", k.Version)
+		}
+		enc.keys[k.Version] = k.Key
+		enc.current = k.Version
+	}
+	return enc, nil
+}
+
+// Encode 使用当前密钥混淆id
+func (enc *Encoder) Encode(id uint64) (uint64, error) {
+	if enc.bits < 64 && id>>enc.bits != 0 {
+		return 0, fmt.Errorf("%w: id %d, bits %d", ErrEncodeOverflow, id, enc.bits)
+	}
+	return enc.current<<enc.bits | enc.permute(enc.keys[enc.current], id, false), nil
+}
+
+// Decode 还原混淆后的id
+func (enc *Encoder) Decode(encoded uint64) (uint64, error) {
+	var version uint64
+	if enc.bits < 64 {
+		version = encoded >> enc.bits
+		encoded &= 1<<enc.bits - 1
+	}
+	key, ok := enc.keys[version]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+	return enc.permute(key, encoded, true), nil
+}
+
+// permute 在bits位上置换，位宽为奇数时在bits+1位的平衡Feistel网络上cycle walking，直至结果落在bits位内
+func (enc *Encoder) permute(key []byte, x uint64, inverse bool) uint64 {
+	half := (enc.bits + 1) / 2
+	for {
+		x = feistel(key, half, x, inverse)
+		if half*2 == enc.bits || x>>enc.bits == 0 {
+			return x
+		}
+	}
+}
+
+func feistel(key []byte, half uint, x uint64, inverse bool) uint64 {
+	mask := uint64(1)<<half - 1
+	l, r := x>>half&mask, x&mask
+	mac := hmac.New(sha256.New, key)
+	var buf [9]byte
+	round := func(i int, v uint64) uint64 {
+		buf[0] = byte(i)
+		binary.BigEndian.PutUint64(buf[1:], v)
+		mac.Reset()
+		_, _ = mac.Write(buf[:])
+		return binary.BigEndian.Uint64(mac.Sum(nil)) & mask
+	}
+	if inverse {
+		for i := feistelRounds - 1; i >= 0; i-- {
+			l, r = r^round(i, l), l
+		}
+	} else {
+		for i := 0; i < feistelRounds; i++ {
+			l, r = r, l^round(i, r)
+		}
+	}
+	return l<<half | r
+}
+
+// ObfuscatedEngine 对Engine生成的id进行混淆，Next等方法仍返回原始id
+type ObfuscatedEngine struct {
+	Engine
+	encoder *Encoder
+}
+
+// NewObfuscatedEngine 新建ObfuscatedEngine
+func NewObfuscatedEngine(e Engine, encoder *Encoder) *ObfuscatedEngine {
+	return &ObfuscatedEngine{Engine: e, encoder: encoder}
+}
+
+// NextEncoded 取唯一id并混淆
+func (e *ObfuscatedEngine) NextEncoded(ctx context.Context) (uint64, error) {
+	id, err := e.NextContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return e.encoder.Encode(id)
+}
+
+// MustNextEncoded 取唯一id并混淆，若发生错误，则会panic
+func (e *ObfuscatedEngine) MustNextEncoded() uint64 {
+	id, err := e.NextEncoded(context.Background())
+	panicIfErr(err)
+	return id
+}
+
+// Decode 还原混淆后的id
+func (e *ObfuscatedEngine) Decode(encoded uint64) (uint64, error) {
+	return e.encoder.Decode(encoded)
+}
@@ -0,0 +1,104 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	encoderKey1 = EncoderKey{Version: 1, Key: []byte("0123456789abcdef")}
+	encoderKey2 = EncoderKey{Version: 2, Key: []byte("fedcba9876543210")}
+)
+
+func TestEncoder(t *testing.T) {
+	Convey("encoder", t, func() {
+		Convey("permutation", func() {
+			for _, bits := range []uint{9, 10} {
+				enc, err := NewEncoder(bits, 0, EncoderKey{Key: encoderKey1.Key})
+				So(err, ShouldBeNil)
+				seen := make(map[uint64]bool)
+				identity := 0
+				for id := uint64(0); id < 1<<bits; id++ {
+					encoded, err := enc.Encode(id)
+					So(err, ShouldBeNil)
+					So(encoded, ShouldBeLessThan, 1<<bits)
+					So(seen[encoded], ShouldBeFalse)
+					seen[encoded] = true
+					if encoded == id {
+						identity++
+					}
+					decoded, err := enc.Decode(encoded)
+					So(err, ShouldBeNil)
+					So(decoded, ShouldEqual, id)
+				}
+				So(identity, ShouldBeLessThan, 10)
+				_, err = enc.Encode(1 << bits)
+				So(errors.Is(err, ErrEncodeOverflow), ShouldBeTrue)
+			}
+		})
+
+		Convey("full width", func() {
+			enc, err := NewEncoder(64, 0, EncoderKey{Key: encoderKey1.Key})
+			So(err, ShouldBeNil)
+			for _, id := range []uint64{0, 1, 30000001, 1<<64 - 1} {
+				encoded, _ := enc.Encode(id)
+				decoded, err := enc.Decode(encoded)
+				So(err, ShouldBeNil)
+				So(decoded, ShouldEqual, id)
+			}
+		})
+
+		Convey("key rotation", func() {
+			old, err := NewEncoder(48, 4, encoderKey1)
+			So(err, ShouldBeNil)
+			encodedOld, _ := old.Encode(30000001)
+			So(encodedOld>>48, ShouldEqual, 1)
+
+			rotated, err := NewEncoder(48, 4, encoderKey1, encoderKey2)
+			So(err, ShouldBeNil)
+			encodedNew, _ := rotated.Encode(30000001)
+			So(encodedNew>>48, ShouldEqual, 2)
+			So(encodedNew&(1<<48-1), ShouldNotEqual, encodedOld&(1<<48-1))
+			for _, encoded := range []uint64{encodedOld, encodedNew} {
+				decoded, err := rotated.Decode(encoded)
+				So(err, ShouldBeNil)
+				So(decoded, ShouldEqual, 30000001)
+			}
+			_, err = old.Decode(encodedNew)
+			So(errors.Is(err, ErrUnknownKeyVersion), ShouldBeTrue)
+		})
+
+		Convey("invalid", func() {
+			_, err := NewEncoder(1, 0, encoderKey1)
+			So(err, ShouldNotBeNil)
+			_, err = NewEncoder(62, 4, encoderKey1)
+			So(err, ShouldNotBeNil)
+			_, err = NewEncoder(48, 0, encoderKey1)
+			So(err, ShouldNotBeNil)
+			_, err = NewEncoder(48, 4, EncoderKey{Version: 1, Key: []byte("short")})
+			So(err, ShouldNotBeNil)
+			_, err = NewEncoder(48, 4, encoderKey1, encoderKey1)
+			So(err, ShouldNotBeNil)
+			_, err = NewEncoder(48, 4)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("obfuscated engine", func() {
+			b := NewWithDriver(getDummyDriver(), NewConfig(WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			e, _ := b.Build("encoder")
+			enc, _ := NewEncoder(48, 4, encoderKey1)
+			oe := NewObfuscatedEngine(e, enc)
+			first := oe.MustNextEncoded()
+			second, err := oe.NextEncoded(context.Background())
+			So(err, ShouldBeNil)
+			a, _ := oe.Decode(first)
+			b2, _ := oe.Decode(second)
+			So(b2, ShouldEqual, a+1)
+			So(second, ShouldNotEqual, first+1)
+		})
+	})
+}
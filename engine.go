@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/sandwich-go/boost/retry"
 	"github.com/sandwich-go/boost/xsync"
 	"github.com/sandwich-go/boost/z"
 	"github.com/sandwich-go/logbus"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 const (
@@ -68,10 +69,14 @@ func Drivers() []string {
 }
 
 type builder struct {
-	driver        Driver
+	driver        Driver // 包装了中间件的Driver
+	origin        Driver // 未包装的Driver，用于查询可选接口
 	visitor       OptionsVisitor
 	engineGetters *sync.Map
 	flag          xsync.AtomicInt32
+	prepareMu     sync.Mutex // 串行化Prepare
+	journal       *journal
+	breaker       *circuitBreaker // 未启用熔断时为nil
 }
 
 func New(driverName string, opts *Options) Builder {
@@ -85,7 +90,27 @@ func New(driverName string, opts *Options) Builder {
 }
 
 func NewWithDriver(driver Driver, opts *Options) Builder {
-	b := &builder{driver: driver, engineGetters: &sync.Map{}, visitor: opts}
+	if opts.GetClampOptions() {
+		// 修正副本，不修改调用方的Options
+		clamped := *opts
+		clampOptions(&clamped)
+		opts = &clamped
+	}
+	b := &builder{origin: driver, engineGetters: &sync.Map{}, visitor: opts}
+	if opts.GetEnableHotReload() {
+		b.visitor = newHotReloadVisitor(opts)
+	}
+	if setter, ok := driver.(optionsVisitorSetter); ok {
+		setter.setOptionsVisitor(b.visitor)
+	}
+	mws := opts.GetMiddlewares()
+	if opts.GetEnableBuiltinMiddlewares() {
+		if threshold := opts.GetCircuitBreakerThreshold(); threshold > 0 {
+			b.breaker = newCircuitBreaker(threshold, opts.GetCircuitBreakerCoolDown())
+		}
+		mws = append(mws[:len(mws):len(mws)], builtinMiddlewares(b.visitor, b.breaker)...)
+	}
+	b.driver = Chain(driver, mws...)
 	return b
 }
 
@@ -97,7 +122,7 @@ func (b *builder) Range(f func(domain string, engine Engine) bool) {
 	})
 }
 
-func (b *builder) getEngineGetterByDomain(domain string, offsetOnCreate uint64) engineGetter {
+func (b *builder) getEngineGetterByDomain(domain string, offsetOnCreate uint64, visitor OptionsVisitor) engineGetter {
 	if f, ok := b.engineGetters.Load(domain); ok {
 		return f.(engineGetter)
 	}
@@ -112,7 +137,7 @@ func (b *builder) getEngineGetterByDomain(domain string, offsetOnCreate uint64)
 	if loaded {
 		return f.(engineGetter)
 	}
-	e = &engine{builder: b, domain: domain, offsetOnCreate: offsetOnCreate}
+	e = newEngine(b, domain, offsetOnCreate, visitor)
 	wg.Done()
 	getter := func() Engine {
 		return e
@@ -136,58 +161,229 @@ func (b *builder) Build(domain string) (Engine, error) {
 }
 
 func (b *builder) BuildWithOffset(domain string, offsetOnCreate uint64) (Engine, error) {
+	return b.build(domain, offsetOnCreate)
+}
+
+func (b *builder) BuildWithOptions(domain string, opts ...DomainOption) (Engine, error) {
+	return b.build(domain, 0, opts...)
+}
+
+func (b *builder) build(domain string, offsetOnCreate uint64, opts ...DomainOption) (Engine, error) {
 	if err := b.checkAvailableFlag(); err != nil {
 		return nil, err
 	}
+	if f, ok := b.engineGetters.Load(domain); ok {
+		return f.(engineGetter)(), nil
+	}
+	visitor := newDomainVisitor(b.visitor, domain, opts...)
+	if err := validateOptions(visitor); err != nil {
+		return nil, fmt.Errorf("domain %s: %w", domain, err)
+	}
 	if offsetOnCreate == 0 {
-		offsetOnCreate = b.visitor.GetOffsetWhenAutoCreateDomain()
+		offsetOnCreate = visitor.GetOffsetWhenAutoCreateDomain()
+	}
+	e := b.getEngineGetterByDomain(domain, offsetOnCreate, visitor)()
+	if visitor.GetWarmupOnBuild() {
+		// Build无ctx参数，预取以RenewTimeout为限，driver无响应时不会阻塞Build
+		ctx, cancel := context.WithTimeout(context.Background(), visitor.GetRenewTimeout())
+		err := e.(*engine).warmup(ctx)
+		cancel()
+		if err != nil {
+			// 预取失败不影响使用，首次Next时仍会renew
+			logbus.Warn(w("warmup failed"), logbus.String("domain", domain), logbus.ErrorField(err))
+		}
+	}
+	return e, nil
+}
+
+func (b *builder) Warmup(ctx context.Context, domains ...string) error {
+	var firstErr error
+	for _, domain := range domains {
+		e, err := b.Build(domain)
+		if err == nil {
+			err = e.(*engine).warmup(ctx)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("warmup domain %s: %w", domain, err)
+		}
 	}
-	return b.getEngineGetterByDomain(domain, offsetOnCreate)(), nil
+	return firstErr
 }
 
 func (b *builder) Prepare(ctx context.Context) error {
-	if b.flag.CompareAndSwap(driverFlagInit, driverFlagInited) {
-		return b.driver.Prepare(ctx)
+	if err := validateOptions(b.visitor); err != nil {
+		return err
+	}
+	b.prepareMu.Lock()
+	defer b.prepareMu.Unlock()
+	switch b.flag.Get() {
+	case driverFlagInited:
+		return nil
+	case driverFlagClosed:
+		return ErrorDriverHasClosed
+	}
+	hotReload := b.visitor.GetEnableHotReload()
+	if hotReload {
+		if err := bindHotReload(); err != nil {
+			return err
+		}
 	}
-	if b.flag.Get() == driverFlagInited {
+	// 日志及Driver均准备成功后才标记为已初始化，失败时可重新Prepare
+	var j *journal
+	if path := b.visitor.GetSegmentJournal(); path != "" {
+		var err error
+		if j, err = openJournal(path); err != nil {
+			if hotReload {
+				unbindHotReload()
+			}
+			return err
+		}
+	}
+	if err := b.driver.Prepare(ctx); err != nil {
+		if hotReload {
+			unbindHotReload()
+		}
+		if j != nil {
+			if err0 := j.close(); err0 != nil {
+				logbus.Error(w("close segment journal failed"), logbus.ErrorField(err0))
+			}
+		}
+		return err
+	}
+	b.journal = j
+	b.flag.Set(driverFlagInited)
+	return nil
+}
+
+// segmentReturner 优先经由中间件链归还号段，中间件未透传SegmentReturner时直接使用原始Driver
+func (b *builder) segmentReturner() SegmentReturner {
+	if returner, ok := b.driver.(SegmentReturner); ok {
+		return returner
+	}
+	returner, ok := b.origin.(SegmentReturner)
+	if !ok {
 		return nil
 	}
-	return ErrorDriverHasClosed
+	logbus.Warn(w("driver middleware hides SegmentReturner, return segments through the origin driver"))
+	return returner
 }
 
 func (b *builder) Destroy(ctx context.Context) error {
 	if b.flag.CompareAndSwap(driverFlagInited, driverFlagClosed) {
+		returner := b.segmentReturner()
+		b.Range(func(_ string, e Engine) bool {
+			e.(*engine).release(ctx, returner)
+			return true
+		})
+		if b.journal != nil {
+			if err := b.journal.close(); err != nil {
+				logbus.Error(w("close segment journal failed"), logbus.ErrorField(err))
+			}
+		}
+		if b.visitor.GetEnableHotReload() {
+			unbindHotReload()
+		}
 		return b.driver.Destroy(ctx)
 	}
 	return b.checkAvailableFlag()
 }
 
+// segment 号段(n, max]，n为已分配的最大id，原子递增，并发取id时可能超过max
+// 号段发布后max及critical不再改变，切换号段时整体替换
+type segment struct {
+	n        uint64
+	max      uint64
+	critical uint64 // 取id时n越过critical，则异步renew下一号段
+}
+
+// take 从号段中取一个id，ok为false表示号段已耗尽
+func (s *segment) take() (id uint64, crossed, ok bool) {
+	id = atomic.AddUint64(&s.n, 1)
+	return id, id-1 == s.critical, id <= s.max
+}
+
+// takeRange 从号段中取n个连续的id
+func (s *segment) takeRange(n uint64) (first, last uint64, crossed, ok bool) {
+	for {
+		c := atomic.LoadUint64(&s.n)
+		if c >= s.max || s.max-c < n {
+			return 0, 0, false, false
+		}
+		if atomic.CompareAndSwapUint64(&s.n, c, c+n) {
+			return c + 1, c + n, c <= s.critical && s.critical < c+n, true
+		}
+	}
+}
+
+// current 返回已分配的最大id，不超过max
+func (s *segment) current() uint64 {
+	if n := atomic.LoadUint64(&s.n); n < s.max {
+		return n
+	}
+	return s.max
+}
+
+// close 关闭号段，之后不能再从号段中取id，返回关闭前已分配的最大id
+func (s *segment) close() uint64 {
+	for {
+		n := atomic.LoadUint64(&s.n)
+		if n >= s.max {
+			return s.max
+		}
+		if atomic.CompareAndSwapUint64(&s.n, n, s.max) {
+			return n
+		}
+	}
+}
+
 type engine struct {
 	builder        *builder
+	visitor        OptionsVisitor // 叠加了domain级别配置的Options
 	domain         string
 	offsetOnCreate uint64
 
-	// current
-	n        uint64 // 当前值
-	max      uint64
-	quantum  uint64
-	ts       z.MonoTimeDuration
-	critical uint64
+	// current，取id时无锁，仅在号段耗尽时加nextMutex切换号段
+	current unsafe.Pointer     // *segment
+	quantum uint64             // 当前号段的段长，renewMutex保护
+	ts      z.MonoTimeDuration // 当前号段投入使用的时间点，renewMutex保护
 
-	// next
+	// next，renewMutex保护
 	nextN       uint64
 	nextMax     uint64
 	nextQuantum uint64
 
-	nextMutex  sync.RWMutex
-	renewMutex sync.RWMutex
+	nextMutex  mutex // 切换号段
+	renewMutex mutex // 申请号段
 
 	renewCount    xsync.AtomicUint64
 	renewErrCount xsync.AtomicUint64
 }
 
+func newEngine(b *builder, domain string, offsetOnCreate uint64, visitor OptionsVisitor) *engine {
+	e := &engine{builder: b, visitor: visitor, domain: domain, offsetOnCreate: offsetOnCreate, nextMutex: newMutex(), renewMutex: newMutex()}
+	seg := &segment{}
+	if b.journal != nil {
+		if s, ok := b.journal.take(domain); ok {
+			// 首次Next即异步renew，已有下一号段时跳过
+			seg = &segment{n: s.N, max: s.Max, critical: s.N}
+			e.nextN, e.nextMax = s.NextN, s.NextMax
+			logbus.Info(w("resume segment from journal"), logbus.String("domain", domain),
+				logbus.Uint64("n", s.N), logbus.Uint64("max", s.Max),
+				logbus.Uint64("nextN", e.nextN), logbus.Uint64("nextMax", e.nextMax))
+		}
+	}
+	e.current = unsafe.Pointer(seg)
+	return e
+}
+
+func (e *engine) segment() *segment { return (*segment)(atomic.LoadPointer(&e.current)) }
+
 func (e *engine) Next() (uint64, error) {
-	return e.NextN(1)
+	return e.NextNContext(context.Background(), 1)
+}
+
+func (e *engine) NextContext(ctx context.Context) (uint64, error) {
+	return e.NextNContext(ctx, 1)
 }
 
 func (e *engine) MustNext() uint64 {
@@ -203,30 +399,99 @@ func (e *engine) MustNextN(n int) uint64 {
 }
 
 func (e *engine) NextN(n int) (uint64, error) {
+	return e.NextNContext(context.Background(), n)
+}
+
+func (e *engine) NextNContext(ctx context.Context, n int) (uint64, error) {
 	if n <= 0 {
 		n = 1
 	}
 	now := z.MonoOffset()
-	// lock-free swap current and next ID bucket if we really really really really really need that
-	e.nextMutex.Lock()
 	var err error
 	var id uint64
-	// 需要优化,todo
 	for i := 0; i < n; i++ {
-		id, err = e.safeNextOne()
+		id, err = e.nextOne(ctx)
 		if err != nil {
 			break
 		}
 	}
-	e.nextMutex.Unlock()
 	e.nextReport(n, now, err)
-	return id, err
+	if err != nil {
+		return 0, err
+	}
+	return regionID(e.visitor, id), nil
+}
+
+func (e *engine) NextRange(ctx context.Context, n uint64) (first, last uint64, err error) {
+	if regionEnabled(e.visitor) {
+		// [first, last]之间的大部分id属于其他区域，调用方按连续区间使用会与其他区域冲突
+		return 0, 0, ErrRangeNotContiguous
+	}
+	if n == 0 {
+		n = 1
+	}
+	now := z.MonoOffset()
+	first, last, err = e.nextRange(ctx, n)
+	e.nextReport(int(n), now, err)
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+func (e *engine) nextRange(ctx context.Context, n uint64) (first, last uint64, err error) {
+	// 当前号段足够，直接从当前号段中切分
+	if first, last, crossed, ok := e.segment().takeRange(n); ok {
+		// 跨过临界值critical时，同nextOne一样异步renew下一个号段
+		if crossed {
+			e.renewAsync()
+		}
+		e.leftReport(last)
+		if last > regionLimitation(e.visitor) {
+			logbus.Error(w("next range failed"), logbus.String("reason", "max id"), logbus.String("domain", e.domain))
+			return 0, 0, ErrReachIdLimitation
+		}
+		return first, last, nil
+	}
+	// 当前号段不足，向Driver申请一段长度为n的独立号段，不影响当前号段与下一号段
+	if err = e.renewMutex.LockContext(ctx); err != nil {
+		return 0, 0, e.wrapContextErr(err)
+	}
+	defer e.renewMutex.Unlock()
+	begin := z.MonoOffset()
+	c, err := e.renew(ctx, n)
+	e.postRenew(c, n, begin, err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, 0, e.wrapContextErr(ctx.Err())
+		}
+		if e.circuitState() == CircuitOpen {
+			return 0, 0, ErrRenewCircuitOpen
+		}
+		return 0, 0, err
+	}
+	// 大段需求计入流控，使后续renew的号段尽快增长
+	if n > e.quantum {
+		e.quantum = n
+	}
+	first, last = c+1, c+n
+	if last > regionLimitation(e.visitor) {
+		logbus.Error(w("next range failed"), logbus.String("reason", "max id"), logbus.String("domain", e.domain))
+		return 0, 0, ErrReachIdLimitation
+	}
+	return first, last, nil
 }
 
 func (e *engine) Stats() Stats {
-	e.nextMutex.Lock()
-	defer e.nextMutex.Unlock()
-	return Stats{Current: e.n, Max: e.max, RenewCount: e.renewCount.Get(), RenewErrCount: e.renewErrCount.Get()}
+	seg := e.segment()
+	return Stats{Current: regionID(e.visitor, seg.current()), Max: regionID(e.visitor, seg.max), RenewCount: e.renewCount.Get(), RenewErrCount: e.renewErrCount.Get(), CircuitState: e.circuitState()}
+}
+
+func (e *engine) circuitState() CircuitState {
+	if e.builder.breaker == nil {
+		return CircuitClosed
+	}
+	return e.builder.breaker.State(e.domain)
 }
 
 func nextQuantum(lastQuantum uint64, segmentTime z.MonoTimeDuration, segmentDuration time.Duration, minQuantum, maxQuantum uint64) uint64 {
@@ -256,98 +521,235 @@ func nextQuantum(lastQuantum uint64, segmentTime z.MonoTimeDuration, segmentDura
 
 func (e *engine) preRenew() (quantum uint64, begin z.MonoTimeDuration) {
 	begin = z.MonoOffset()
-	quantum = nextQuantum(e.quantum, e.ts,
-		e.builder.visitor.GetSegmentDuration(),
-		e.builder.visitor.GetMinQuantum(),
-		e.builder.visitor.GetMaxQuantum(),
+	lastQuantum := e.quantum
+	if lastQuantum == 0 {
+		// 首个号段使用InitialQuantum
+		lastQuantum = e.visitor.GetInitialQuantum()
+	}
+	quantum = nextQuantum(lastQuantum, e.ts,
+		e.visitor.GetSegmentDuration(),
+		e.visitor.GetMinQuantum(),
+		e.visitor.GetMaxQuantum(),
 	)
 	return
 }
 
-func (e *engine) postRenew(quantum uint64, begin z.MonoTimeDuration, err error) {
-	e.renewReport(quantum, begin, err)
+func (e *engine) postRenew(curr, quantum uint64, begin z.MonoTimeDuration, err error) {
+	e.renewReport(curr, quantum, begin, err)
 }
 
-func (e *engine) renewWithUnlock() error {
-	defer e.renewMutex.Unlock()
+// renewNext 申请下一号段，调用方须持有renewMutex
+func (e *engine) renewNext(ctx context.Context) error {
 	quantum, begin := e.preRenew()
-	err := retry.Do(func(attempt uint) (errRetry error) {
-		defer func() {
-			if r := recover(); r != nil {
-				errRetry = fmt.Errorf("panic %v", r)
-				logbus.Error(w("renew panic"), logbus.Uint("attempt", attempt), logbus.String("domain", e.domain),
-					logbus.Any("recover", r))
-			}
-		}()
-		ctx, cancel := context.WithTimeout(context.Background(), e.builder.visitor.GetRenewTimeout())
-		defer cancel()
-		c, err := e.builder.driver.Renew(ctx, e.domain, quantum, e.offsetOnCreate)
-		if err != nil {
-			errRetry = err
-			return errRetry
-		}
+	return e.renewNextWithQuantum(ctx, quantum, begin)
+}
+
+// renewNextWithQuantum 以指定段长申请下一号段，调用方须持有renewMutex
+func (e *engine) renewNextWithQuantum(ctx context.Context, quantum uint64, begin z.MonoTimeDuration) error {
+	c, err := e.renew(ctx, quantum)
+	if err == nil {
 		e.nextN = c
 		e.nextMax = c + quantum
 		e.nextQuantum = quantum
-		return nil
-	},
-		retry.WithLimit(e.builder.visitor.GetRenewRetry()),
-		retry.WithDelayType(func(n uint, _ error, _ *retry.Options) time.Duration {
-			return time.Duration(n) * e.builder.visitor.GetRenewRetryDelay()
-		}))
-	e.postRenew(quantum, begin, err)
+		if e.builder.journal != nil {
+			e.builder.journal.saveNext(e.domain, e.nextN, e.nextMax)
+		}
+	}
+	e.postRenew(c, quantum, begin, err)
 	return err
 }
 
-func (e *engine) safeNextOne() (uint64, error) {
-	id, err := e.nextOne()
-	if err != nil && err == ErrIdRunOut {
-		logbus.Warn(w("retry renew"), logbus.String("reason", "id run out"), logbus.String("domain", e.domain))
-		e.renewMutex.Lock()
-		if err0 := e.renewWithUnlock(); err0 == nil {
-			id, err = e.nextOne()
+// renew 向Driver申请一段长度为quantum的id，返回当前id
+// 重试、panic恢复及超时由Driver中间件完成，见builtinMiddlewares
+func (e *engine) renew(ctx context.Context, quantum uint64) (uint64, error) {
+	return e.builder.driver.Renew(ctx, e.domain, quantum, e.offsetOnCreate)
+}
+
+// release 关闭时释放engine持有的号段，之后engine不再使用这些号段
+// 若Driver实现了SegmentReturner，先归还最近renew的下一号段，两段连续时才能继续归还当前号段
+// 未能归还的号段记录到本地号段日志中
+func (e *engine) release(ctx context.Context, returner SegmentReturner) {
+	if err := e.nextMutex.LockContext(ctx); err != nil {
+		e.releaseFailed(err)
+		return
+	}
+	defer e.nextMutex.Unlock()
+	if err := e.renewMutex.LockContext(ctx); err != nil {
+		e.releaseFailed(err)
+		return
+	}
+	defer e.renewMutex.Unlock()
+	seg := e.segment()
+	n, max := seg.close(), seg.max
+	if returner != nil {
+		returned := true
+		if e.nextMax != 0 {
+			contiguous := e.nextN == max
+			returned = e.returnSegment(ctx, returner, e.nextN, e.nextMax)
+			if returned {
+				e.nextN, e.nextMax = 0, 0
+			}
+			returned = returned && contiguous
+		}
+		if returned && max > n && e.returnSegment(ctx, returner, n, max) {
+			max = n
 		}
 	}
-	return id, err
+	if e.builder.journal != nil {
+		e.builder.journal.put(journalSegment{Domain: e.domain, N: n, Max: max, NextN: e.nextN, NextMax: e.nextMax})
+	}
+	atomic.StorePointer(&e.current, unsafe.Pointer(&segment{n: n, max: n}))
+	e.nextN, e.nextMax = 0, 0
 }
 
-func (e *engine) nextOne() (uint64, error) {
-	if e.n == e.critical {
-		e.renewMutex.Lock()
-		if e.n == 0 {
-			_ = e.renewWithUnlock()
-		} else {
-			go func() { _ = e.renewWithUnlock() }()
+func (e *engine) releaseFailed(err error) {
+	logbus.Warn(w("release segment failed"), logbus.ErrorField(err), logbus.String("domain", e.domain))
+	if e.builder.journal != nil {
+		e.builder.journal.remove(e.domain)
+	}
+}
+
+func (e *engine) returnSegment(ctx context.Context, returner SegmentReturner, n, max uint64) bool {
+	ok, err := returner.ReturnSegment(ctx, e.domain, n, max)
+	if err != nil {
+		logbus.Warn(w("return segment failed"), logbus.ErrorField(err), logbus.String("domain", e.domain),
+			logbus.Uint64("n", n), logbus.Uint64("max", max))
+	} else if ok && e.visitor.GetDevelopment() {
+		logbus.Debug(w("return segment ok"), logbus.Uint64("n", n), logbus.Uint64("max", max), logbus.String("domain", e.domain))
+	}
+	return ok && err == nil
+}
+
+func (e *engine) wrapContextErr(err error) error {
+	return fmt.Errorf("[%s]: domain %s, %w", tag, e.domain, err)
+}
+
+// nextOne 从当前号段中无锁取id，号段耗尽时切换到下一号段
+func (e *engine) nextOne(ctx context.Context) (uint64, error) {
+	id, crossed, ok := e.segment().take()
+	if !ok {
+		return e.nextSlow(ctx)
+	}
+	if crossed {
+		e.renewAsync()
+	}
+	return e.checkId(id)
+}
+
+// nextSlow 当前号段耗尽，等待renew结束后切换到下一号段
+func (e *engine) nextSlow(ctx context.Context) (uint64, error) {
+	if err := e.nextMutex.LockContext(ctx); err != nil {
+		return 0, e.wrapContextErr(err)
+	}
+	defer e.nextMutex.Unlock()
+	// 等待期间其他调用方可能已切换号段
+	seg := e.segment()
+	if id, crossed, ok := seg.take(); ok {
+		if crossed {
+			e.renewAsync()
 		}
+		return e.checkId(id)
 	}
-	if e.max < e.n+1 {
-		// wait until renew finished, swap to the next id bucket
-		e.renewMutex.Lock()
-		defer e.renewMutex.Unlock()
-		if e.nextMax == 0 {
+	if err := e.renewMutex.LockContext(ctx); err != nil {
+		return 0, e.wrapContextErr(err)
+	}
+	defer e.renewMutex.Unlock()
+	if e.nextMax == 0 {
+		// 熔断期间不再renew，直接返回
+		if e.circuitState() == CircuitOpen {
+			return 0, ErrRenewCircuitOpen
+		}
+		if seg.max != 0 {
+			logbus.Warn(w("retry renew"), logbus.String("reason", "id run out"), logbus.String("domain", e.domain))
+		}
+		if err := e.renewNext(ctx); err != nil {
+			if ctx.Err() != nil {
+				return 0, e.wrapContextErr(ctx.Err())
+			}
+			if e.circuitState() == CircuitOpen {
+				return 0, ErrRenewCircuitOpen
+			}
 			logbus.Error(w("next failed"), logbus.String("reason", "id run out"), logbus.String("domain", e.domain))
 			return 0, ErrIdRunOut
 		}
-		e.n = e.nextN
-		e.max = e.nextMax
-		e.quantum = e.nextQuantum
-		e.useNewQuantumReport()
-		// 记录号段正式投入使用的时间点
-		e.ts = z.MonoOffset()
-		// 计算renew临界值critical,renewCount不能为0,否则无法触发renew机制
-		renewCount := (e.max - e.n) * uint64(e.builder.visitor.GetRenewPercent()) / 100
-		if renewCount == 0 {
-			renewCount = 1
-		}
-		e.critical = e.n + renewCount
-		e.nextMax = 0
-		e.nextN = 0
 	}
-	e.n++
-	e.leftReport()
-	if e.n > e.builder.visitor.GetLimitation() {
+	// 新号段的critical不小于首个id，无需异步renew
+	id, _, _ := e.useNext().take()
+	return e.checkId(id)
+}
+
+func (e *engine) checkId(id uint64) (uint64, error) {
+	e.leftReport(id)
+	if id > regionLimitation(e.visitor) {
 		logbus.Error(w("next failed"), logbus.String("reason", "max id"), logbus.String("domain", e.domain))
 		return 0, ErrReachIdLimitation
 	}
-	return e.n, nil
+	return id, nil
+}
+
+// renewAsync 异步renew下一号段，renewMutex已被占用（其他goroutine正在renew）时直接返回，不阻塞取id
+// 未能renew的下一号段在当前号段耗尽时同步renew
+func (e *engine) renewAsync() {
+	if !e.renewMutex.TryLock() {
+		return
+	}
+	go func() {
+		defer e.renewMutex.Unlock()
+		// 下一号段已就绪，例如已预热或从号段日志恢复
+		if e.nextMax != 0 {
+			return
+		}
+		_ = e.renewNext(context.Background())
+	}()
+}
+
+// useNext 切换到下一号段并返回，调用方须持有nextMutex及renewMutex
+func (e *engine) useNext() *segment {
+	if e.builder.journal != nil {
+		e.builder.journal.saveCurrent(e.domain, e.nextN, e.nextMax)
+	}
+	// 计算renew临界值critical,renewCount不能为0,否则无法触发renew机制
+	renewCount := (e.nextMax - e.nextN) * uint64(e.visitor.GetRenewPercent()) / 100
+	if renewCount == 0 {
+		renewCount = 1
+	}
+	seg := &segment{n: e.nextN, max: e.nextMax, critical: e.nextN + renewCount}
+	e.quantum = e.nextQuantum
+	// 记录号段正式投入使用的时间点
+	e.ts = z.MonoOffset()
+	atomic.StorePointer(&e.current, unsafe.Pointer(seg))
+	e.useNewQuantumReport(seg.max)
+	e.nextMax = 0
+	e.nextN = 0
+	return seg
+}
+
+// warmup 预取当前号段及下一号段，使之后的Next无需等待renew
+func (e *engine) warmup(ctx context.Context) error {
+	if err := e.nextMutex.LockContext(ctx); err != nil {
+		return e.wrapContextErr(err)
+	}
+	defer e.nextMutex.Unlock()
+	if err := e.renewMutex.LockContext(ctx); err != nil {
+		return e.wrapContextErr(err)
+	}
+	defer e.renewMutex.Unlock()
+	used := false
+	if seg := e.segment(); seg.current() >= seg.max {
+		if e.nextMax == 0 {
+			if err := e.renewNext(ctx); err != nil {
+				return err
+			}
+		}
+		e.useNext()
+		used = true
+	}
+	if e.nextMax != 0 {
+		return nil
+	}
+	if used {
+		// 号段刚投入使用，按流量增长期计算会使段长翻倍，预取的下一号段沿用当前段长
+		return e.renewNextWithQuantum(ctx, e.quantum, z.MonoOffset())
+	}
+	return e.renewNext(ctx)
 }
@@ -2,6 +2,7 @@ package siid
 
 import (
 	"context"
+	"errors"
 	"github.com/sandwich-go/boost/z"
 	. "github.com/smartystreets/goconvey/convey"
 	"sync"
@@ -84,25 +85,26 @@ func TestSIID(t *testing.T) {
 }
 
 var (
-	once                             sync.Once
+	dummyOnce, mysqlOnce             sync.Once
 	dummyDriverName, mysqlDriverName = "dummy", "mysql"
 )
 
-func initBenchmark() {
-	once.Do(func() {
-		Register(dummyDriverName, getDummyDriver())
-		Register(mysqlDriverName, getMysqlDriver(mysqlAddress))
-	})
+func initBenchmark(driverName string) {
+	dummyOnce.Do(func() { Register(dummyDriverName, getDummyDriver()) })
+	if driverName == mysqlDriverName {
+		mysqlOnce.Do(func() { Register(mysqlDriverName, getMysqlDriver(mysqlAddress)) })
+	}
 }
 
 func getBenchmarkEngine(b *testing.B, driverName string) Engine {
-	initBenchmark()
+	initBenchmark(driverName)
 	var domain = "dummy"
 	var quantum uint64 = 1000
 	bd := New(driverName, NewConfig(
 		WithOffsetWhenAutoCreateDomain(defaultOffsetWhenAutoCreateDomain),
 		WithInitialQuantum(quantum),
 		WithDevelopment(false),
+		WithEnableMonitor(false),
 		WithEnableSlow(false)),
 	)
 	err := bd.Prepare(context.Background())
@@ -136,8 +138,8 @@ func BenchmarkSIID_Mysql(b *testing.B) {
 	benchmarkSIID(b, mysqlDriverName)
 }
 
-func BenchmarkParallelSIID_Mysql(b *testing.B) {
-	e := getBenchmarkEngine(b, mysqlDriverName)
+func benchmarkParallelSIID(b *testing.B, driverName string) {
+	e := getBenchmarkEngine(b, driverName)
 	b.ReportAllocs()
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
@@ -149,3 +151,297 @@ func BenchmarkParallelSIID_Mysql(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkParallelSIID_Dummy(b *testing.B) {
+	benchmarkParallelSIID(b, dummyDriverName)
+}
+
+func BenchmarkParallelSIID_Mysql(b *testing.B) {
+	benchmarkParallelSIID(b, mysqlDriverName)
+}
+
+func TestNextRange(t *testing.T) {
+	Convey("next range", t, func() {
+		b := NewWithDriver(getDummyDriver(), NewConfig(
+			WithOffsetWhenAutoCreateDomain(defaultOffsetWhenAutoCreateDomain),
+			WithMinQuantum(100),
+			WithMaxQuantum(1000),
+			WithDevelopment(false)),
+		)
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, err := b.Build("range")
+		So(err, ShouldBeNil)
+
+		// 首次无号段，申请独立号段
+		first, last, err := e.NextRange(context.Background(), 10)
+		So(err, ShouldBeNil)
+		So(first, ShouldEqual, defaultOffsetWhenAutoCreateDomain+1)
+		So(last, ShouldEqual, defaultOffsetWhenAutoCreateDomain+10)
+
+		id, err := e.Next()
+		So(err, ShouldBeNil)
+		So(id, ShouldBeGreaterThan, last)
+
+		// 当前号段足够，直接切分
+		first, last, err = e.NextRange(context.Background(), 5)
+		So(err, ShouldBeNil)
+		So(first, ShouldEqual, id+1)
+		So(last, ShouldEqual, id+5)
+
+		// 超过当前号段，申请独立号段
+		first, last, err = e.NextRange(context.Background(), 5000)
+		So(err, ShouldBeNil)
+		So(last-first+1, ShouldEqual, 5000)
+
+		id, err = e.Next()
+		So(err, ShouldBeNil)
+		So(id, ShouldBeLessThan, first)
+		So(e.Stats().RenewErrCount, ShouldBeZeroValue)
+	})
+}
+
+type blockDriver struct{ Driver }
+
+func (d *blockDriver) Renew(ctx context.Context, _ string, _, _ uint64) (uint64, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestNextContext(t *testing.T) {
+	Convey("next context", t, func() {
+		b := NewWithDriver(&blockDriver{Driver: getDummyDriver()}, NewConfig(
+			WithRenewTimeout(time.Hour),
+			WithDevelopment(false)),
+		)
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, err := b.Build("context")
+		So(err, ShouldBeNil)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				_, errs[i] = e.NextContext(ctx)
+			}(i)
+		}
+		wg.Wait()
+		for _, err0 := range errs {
+			So(errors.Is(err0, context.DeadlineExceeded), ShouldBeTrue)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = e.NextNContext(ctx, 10)
+		So(errors.Is(err, context.Canceled), ShouldBeTrue)
+	})
+}
+
+func TestRenewAsyncNotBlocking(t *testing.T) {
+	Convey("renew async does not block next", t, func() {
+		b := NewWithDriver(getDummyDriver(), NewConfig(WithOffsetWhenAutoCreateDomain(0), WithInitialQuantum(30),
+			WithMinQuantum(30), WithRenewPercent(50), WithDevelopment(false)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, _ := b.Build("async")
+		So(e.MustNext(), ShouldEqual, 1)
+
+		// 模拟其他goroutine正在renew，跨过临界值的调用方不等待renewMutex
+		ee := e.(*engine)
+		ee.renewMutex.Lock()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 20; i++ {
+				_, _ = e.Next()
+			}
+		}()
+		var finished bool
+		select {
+		case <-done:
+			finished = true
+		case <-time.After(time.Second):
+		}
+		ee.renewMutex.Unlock()
+		<-done
+		So(finished, ShouldBeTrue)
+		// 未能异步renew的下一号段在当前号段耗尽时同步renew
+		for i := 0; i < 10; i++ {
+			So(e.MustNext(), ShouldEqual, uint64(22+i))
+		}
+	})
+}
+
+func TestReturnSegmentsOnDestroy(t *testing.T) {
+	Convey("return segments on destroy", t, func() {
+		driver := getDummyDriver()
+		newBuilder := func() Builder {
+			b := NewWithDriver(driver, NewConfig(
+				WithOffsetWhenAutoCreateDomain(defaultOffsetWhenAutoCreateDomain),
+				WithDevelopment(false)),
+			)
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			return b
+		}
+
+		b := newBuilder()
+		e, err := b.Build("return")
+		So(err, ShouldBeNil)
+		var id uint64
+		for i := 0; i < 10; i++ {
+			id, err = e.Next()
+			So(err, ShouldBeNil)
+		}
+		So(b.Destroy(context.Background()), ShouldBeNil)
+		So(driver.mm["return"], ShouldEqual, id)
+
+		// 其他进程已renew，不能归还
+		b1, b2 := newBuilder(), newBuilder()
+		e1, _ := b1.Build("return")
+		e2, _ := b2.Build("return")
+		id1, err := e1.Next()
+		So(err, ShouldBeNil)
+		So(id1, ShouldEqual, id+1)
+		id2, err := e2.Next()
+		So(err, ShouldBeNil)
+		So(b1.Destroy(context.Background()), ShouldBeNil)
+		So(driver.mm["return"], ShouldBeGreaterThan, id2)
+		So(b2.Destroy(context.Background()), ShouldBeNil)
+		So(driver.mm["return"], ShouldEqual, id2)
+
+		Convey("middleware hides SegmentReturner", func() {
+			// 中间件只包装Driver接口，不透传SegmentReturner
+			plain := func(next Driver) Driver { return struct{ Driver }{next} }
+			b := NewWithDriver(driver, NewConfig(WithDevelopment(false), WithMiddlewares(plain)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			e, _ := b.Build("return")
+			id, err := e.Next()
+			So(err, ShouldBeNil)
+			So(b.Destroy(context.Background()), ShouldBeNil)
+			So(driver.mm["return"], ShouldEqual, id)
+		})
+	})
+}
+
+func TestInitialQuantum(t *testing.T) {
+	Convey("initial quantum", t, func() {
+		for _, c := range []struct {
+			initial, min, max, expect uint64
+		}{
+			{100, 30, 3000, 100},
+			{5, 30, 3000, 30},
+			{5000, 30, 3000, 3000},
+		} {
+			b := NewWithDriver(getDummyDriver(), NewConfig(WithOffsetWhenAutoCreateDomain(0), WithDevelopment(false),
+				WithInitialQuantum(c.initial), WithMinQuantum(c.min), WithMaxQuantum(c.max)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			e, _ := b.Build("initial")
+			So(e.MustNext(), ShouldEqual, 1)
+			So(e.Stats().Max, ShouldEqual, c.expect)
+		}
+	})
+}
+
+func TestWarmup(t *testing.T) {
+	Convey("warmup", t, func() {
+		d := &flakyDriver{Driver: getDummyDriver()}
+		b := NewWithDriver(d, NewConfig(WithOffsetWhenAutoCreateDomain(0), WithDevelopment(false), WithEnableBuiltinMiddlewares(false)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+
+		Convey("current and next segment", func() {
+			So(b.Warmup(context.Background(), "a", "b"), ShouldBeNil)
+			So(d.calls, ShouldEqual, 4)
+			So(b.Warmup(context.Background(), "a"), ShouldBeNil)
+			So(d.calls, ShouldEqual, 4)
+
+			e, _ := b.Build("a")
+			So(e.Stats().Max, ShouldEqual, 30)
+			// 当前号段内不再renew
+			for i := uint64(1); i <= 30; i++ {
+				So(e.MustNext(), ShouldEqual, i)
+			}
+			So(d.calls, ShouldEqual, 4)
+			// 切换到预取的下一号段，段长与当前号段相同
+			So(e.MustNext(), ShouldEqual, 31)
+			So(e.Stats().Max, ShouldEqual, 60)
+		})
+
+		Convey("warmup on build", func() {
+			ob := NewWithDriver(d, NewConfig(WithWarmupOnBuild(true), WithOffsetWhenAutoCreateDomain(0),
+				WithDevelopment(false), WithEnableBuiltinMiddlewares(false)))
+			So(ob.Prepare(context.Background()), ShouldBeNil)
+			e, err := ob.Build("c")
+			So(err, ShouldBeNil)
+			So(d.calls, ShouldEqual, 2)
+			So(e.Stats().Max, ShouldEqual, 30)
+		})
+
+		Convey("warmup on build is bounded by renew timeout", func() {
+			ob := NewWithDriver(&blockDriver{Driver: getDummyDriver()}, NewConfig(WithWarmupOnBuild(true),
+				WithRenewTimeout(50*time.Millisecond), WithDevelopment(false), WithEnableBuiltinMiddlewares(false)))
+			So(ob.Prepare(context.Background()), ShouldBeNil)
+			start := time.Now()
+			_, err := ob.Build("blocked")
+			So(err, ShouldBeNil)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+		})
+
+		Convey("renew failed", func() {
+			d.fails = 1
+			err := b.Warmup(context.Background(), "a", "b")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "domain a")
+			// 后续domain仍会预取
+			So(d.calls, ShouldEqual, 3)
+			e, _ := b.Build("a")
+			So(e.MustNext(), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestConcurrentNext(t *testing.T) {
+	Convey("concurrent next", t, func() {
+		// 小号段使号段频繁切换
+		b := NewWithDriver(getDummyDriver(), NewConfig(WithOffsetWhenAutoCreateDomain(0), WithInitialQuantum(7),
+			WithMinQuantum(7), WithMaxQuantum(7), WithRenewPercent(50), WithDevelopment(false)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, _ := b.Build("concurrent")
+
+		const workers, loops = 16, 500
+		ids := make([][]uint64, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < loops; j++ {
+					if j%10 == 0 {
+						first, last, err := e.NextRange(context.Background(), 3)
+						if err == nil {
+							for id := first; id <= last; id++ {
+								ids[i] = append(ids[i], id)
+							}
+						}
+						continue
+					}
+					if id, err := e.Next(); err == nil {
+						ids[i] = append(ids[i], id)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		seen := make(map[uint64]bool)
+		for _, list := range ids {
+			for _, id := range list {
+				So(seen[id], ShouldBeFalse)
+				seen[id] = true
+			}
+		}
+		So(len(seen), ShouldEqual, workers*loops/10*3+workers*loops*9/10)
+		So(e.Stats().Current, ShouldBeLessThanOrEqualTo, e.Stats().Max)
+	})
+}
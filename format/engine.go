@@ -0,0 +1,45 @@
+package format
+
+import (
+	"context"
+
+	"github.com/sandwich-go/siid"
+)
+
+func panicIfErr(err error) {
+	if err != nil {
+		panic("format: " + err.Error())
+	}
+}
+
+// Engine 包装siid.Engine，直接返回编码后的字符串id
+type Engine struct {
+	siid.Engine
+	codec Codec
+}
+
+// NewEngine 新建Engine，codec为字符串id的编码方式
+func NewEngine(e siid.Engine, codec Codec) *Engine {
+	return &Engine{Engine: e, codec: codec}
+}
+
+// NextString 取唯一id并编码为字符串
+func (e *Engine) NextString(ctx context.Context) (string, error) {
+	id, err := e.NextContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return e.codec.Format(id), nil
+}
+
+// MustNextString 取唯一id并编码为字符串，若发生错误，则会panic
+func (e *Engine) MustNextString() string {
+	s, err := e.NextString(context.Background())
+	panicIfErr(err)
+	return s
+}
+
+// Parse 将字符串id解码为id
+func (e *Engine) Parse(s string) (uint64, error) {
+	return e.codec.Parse(s)
+}
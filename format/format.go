@@ -0,0 +1,243 @@
+// Package format 将siid生成的uint64 id编码为便于展示及输入的字符串，例如邀请码、公会码
+// 支持base62、Crockford base32（大小写不敏感，无易混淆字符）及带校验位的形式，Parse会拒绝校验位错误的输入
+package format
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	ErrEmpty       = errors.New("empty id string")
+	ErrInvalidChar = errors.New("invalid character")
+	ErrOverflow    = errors.New("id overflows uint64")
+	ErrCheckDigit  = errors.New("check digit mismatch")
+)
+
+const (
+	base62Alphabet    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	decimalAlphabet   = "0123456789"
+)
+
+// Codec id与字符串的相互转换
+type Codec interface {
+	// Format 将id编码为字符串
+	Format(id uint64) string
+	// Parse 将字符串解码为id
+	Parse(s string) (uint64, error)
+}
+
+var (
+	// Base62 0-9A-Za-z，大小写敏感
+	Base62 Codec = newAlphabetCodec(base62Alphabet, nil)
+	// Crockford32 Crockford base32，输出大写，解析时大小写不敏感，I、L视为1，O视为0，忽略连字符
+	Crockford32 Codec = newAlphabetCodec(crockfordAlphabet, crockfordFold)
+	// Decimal 十进制
+	Decimal Codec = newAlphabetCodec(decimalAlphabet, nil)
+	// DammDecimal 十进制，末尾追加一位Damm校验位，可检出所有单个数字错误及相邻数字换位
+	DammDecimal Codec = dammCodec{}
+)
+
+func crockfordFold(c byte) byte {
+	switch c {
+	case 'I', 'i', 'L', 'l':
+		return '1'
+	case 'O', 'o':
+		return '0'
+	case '-':
+		return 0
+	}
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+type alphabetCodec struct {
+	alphabet string
+	index    [256]int16      // 字符在字母表中的序号，-1表示非法字符
+	fold     func(byte) byte // 解析前规范化字符，返回0表示忽略该字符
+}
+
+func newAlphabetCodec(alphabet string, fold func(byte) byte) *alphabetCodec {
+	c := &alphabetCodec{alphabet: alphabet, fold: fold}
+	for i := range c.index {
+		c.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c.index[alphabet[i]] = int16(i)
+	}
+	return c
+}
+
+func (c *alphabetCodec) base() uint64 { return uint64(len(c.alphabet)) }
+
+func (c *alphabetCodec) Format(id uint64) string {
+	var buf [64]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = c.alphabet[id%c.base()]
+		id /= c.base()
+		if id == 0 {
+			break
+		}
+	}
+	return string(buf[i:])
+}
+
+// digits 将字符串转换为字母表中的序号
+func (c *alphabetCodec) digits(s string) ([]int, error) {
+	digits := make([]int, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if c.fold != nil {
+			if ch = c.fold(ch); ch == 0 {
+				continue
+			}
+		}
+		d := c.index[ch]
+		if d < 0 {
+			return nil, fmt.Errorf("%w: %q in %q", ErrInvalidChar, s[i], s)
+		}
+		digits = append(digits, int(d))
+	}
+	if len(digits) == 0 {
+		return nil, ErrEmpty
+	}
+	return digits, nil
+}
+
+func (c *alphabetCodec) value(s string, digits []int) (uint64, error) {
+	var v uint64
+	for _, d := range digits {
+		if v > (math.MaxUint64-uint64(d))/c.base() {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+		}
+		v = v*c.base() + uint64(d)
+	}
+	return v, nil
+}
+
+func (c *alphabetCodec) Parse(s string) (uint64, error) {
+	digits, err := c.digits(s)
+	if err != nil {
+		return 0, err
+	}
+	return c.value(s, digits)
+}
+
+// luhnCodec Luhn mod N校验，N为字母表长度，可检出所有单个字符错误及绝大多数相邻字符换位
+type luhnCodec struct {
+	*alphabetCodec
+}
+
+// WithLuhn 在codec编码的末尾追加一位Luhn mod N校验字符，codec须为本包提供的Base62、Crockford32或Decimal
+func WithLuhn(codec Codec) Codec {
+	c, ok := codec.(*alphabetCodec)
+	if !ok {
+		panic(fmt.Sprintf("format: WithLuhn unsupported codec %T", codec))
+	}
+	return luhnCodec{alphabetCodec: c}
+}
+
+// luhnSum 从右向左隔位加倍，double为最右一位是否加倍
+func luhnSum(digits []int, n int, double bool) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if double {
+			d *= 2
+			d = d/n + d%n
+		}
+		sum += d
+		double = !double
+	}
+	return sum
+}
+
+func (c luhnCodec) Format(id uint64) string {
+	s := c.alphabetCodec.Format(id)
+	digits, _ := c.digits(s)
+	n := int(c.base())
+	check := (n - luhnSum(digits, n, true)%n) % n
+	return s + c.alphabet[check:check+1]
+}
+
+func (c luhnCodec) Parse(s string) (uint64, error) {
+	digits, err := c.digits(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(digits) < 2 {
+		return 0, fmt.Errorf("%w: %q", ErrCheckDigit, s)
+	}
+	n := int(c.base())
+	if luhnSum(digits, n, false)%n != 0 {
+		return 0, fmt.Errorf("%w: %q", ErrCheckDigit, s)
+	}
+	return c.value(s, digits[:len(digits)-1])
+}
+
+var dammTable = [10][10]int{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+func damm(digits []int) int {
+	interim := 0
+	for _, d := range digits {
+		interim = dammTable[interim][d]
+	}
+	return interim
+}
+
+type dammCodec struct{}
+
+func (dammCodec) Format(id uint64) string {
+	s := Decimal.Format(id)
+	digits, _ := Decimal.(*alphabetCodec).digits(s)
+	return s + decimalAlphabet[damm(digits):damm(digits)+1]
+}
+
+func (dammCodec) Parse(s string) (uint64, error) {
+	c := Decimal.(*alphabetCodec)
+	digits, err := c.digits(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(digits) < 2 || damm(digits) != 0 {
+		return 0, fmt.Errorf("%w: %q", ErrCheckDigit, s)
+	}
+	return c.value(s, digits[:len(digits)-1])
+}
+
+// Group 每size个字符插入一个分隔符，便于阅读，例如Crockford32的`3KT0-9Q2X`，Crockford32解析时会忽略连字符
+func Group(s string, size int, sep string) string {
+	if size <= 0 || len(s) <= size {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i += size {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		end := i + size
+		if end > len(s) {
+			end = len(s)
+		}
+		b.WriteString(s[i:end])
+	}
+	return b.String()
+}
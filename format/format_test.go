@@ -0,0 +1,109 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sandwich-go/siid"
+	"github.com/sandwich-go/siid/siidtest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testIds = []uint64{0, 1, 9, 10, 61, 62, 30000001, 1 << 40, math.MaxUint64}
+
+func TestCodec(t *testing.T) {
+	Convey("codec", t, func() {
+		for _, codec := range []Codec{Base62, Crockford32, Decimal, DammDecimal, WithLuhn(Base62), WithLuhn(Crockford32), WithLuhn(Decimal)} {
+			for _, id := range testIds {
+				s := codec.Format(id)
+				v, err := codec.Parse(s)
+				So(err, ShouldBeNil)
+				So(v, ShouldEqual, id)
+			}
+			_, err := codec.Parse("")
+			So(errors.Is(err, ErrEmpty), ShouldBeTrue)
+		}
+		So(Base62.Format(61), ShouldEqual, "z")
+		So(Base62.Format(62), ShouldEqual, "10")
+		So(Decimal.Format(math.MaxUint64), ShouldEqual, "18446744073709551615")
+
+		_, err := Decimal.Parse("18446744073709551616")
+		So(errors.Is(err, ErrOverflow), ShouldBeTrue)
+		_, err = Base62.Parse("a-b")
+		So(errors.Is(err, ErrInvalidChar), ShouldBeTrue)
+	})
+
+	Convey("crockford32", t, func() {
+		s := Crockford32.Format(30000001)
+		So(s, ShouldEqual, "WKGW1")
+		for _, in := range []string{"wkgw1", "WKGWI", "wkgwl", "WKG-W1"} {
+			v, err := Crockford32.Parse(in)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 30000001)
+		}
+		So(Crockford32.Format(1024), ShouldEqual, "100")
+		v, err := Crockford32.Parse("1oO")
+		So(err, ShouldBeNil)
+		So(v, ShouldEqual, 1024)
+		_, err = Crockford32.Parse("U")
+		So(errors.Is(err, ErrInvalidChar), ShouldBeTrue)
+		So(Group("ABCDEFGHJ", 4, "-"), ShouldEqual, "ABCD-EFGH-J")
+	})
+
+	Convey("check digit", t, func() {
+		So(DammDecimal.Format(572), ShouldEqual, "5724")
+		for _, c := range []struct {
+			codec    Codec
+			alphabet string
+		}{
+			{DammDecimal, decimalAlphabet},
+			{WithLuhn(Decimal), decimalAlphabet},
+			{WithLuhn(Base62), base62Alphabet},
+			{WithLuhn(Crockford32), crockfordAlphabet},
+		} {
+			s := c.codec.Format(30000001)
+			// 任意单个字符错误均可检出
+			for i := 0; i < len(s); i++ {
+				for j := 0; j < len(c.alphabet); j++ {
+					if c.alphabet[j] == s[i] {
+						continue
+					}
+					_, err := c.codec.Parse(s[:i] + c.alphabet[j:j+1] + s[i+1:])
+					So(errors.Is(err, ErrCheckDigit), ShouldBeTrue)
+				}
+			}
+		}
+		// Damm可检出所有相邻数字换位
+		s := DammDecimal.Format(30000001)
+		for i := 0; i+1 < len(s); i++ {
+			if s[i] != s[i+1] {
+				_, err := DammDecimal.Parse(s[:i] + s[i+1:i+2] + s[i:i+1] + s[i+2:])
+				So(errors.Is(err, ErrCheckDigit), ShouldBeTrue)
+			}
+		}
+	})
+}
+
+func TestEngine(t *testing.T) {
+	Convey("format engine", t, func() {
+		b := siid.NewWithDriver(siidtest.NewDriver(), siid.NewConfig(siid.WithDevelopment(false), siid.WithRenewRetryDelay(time.Millisecond)))
+		So(b.Prepare(context.Background()), ShouldBeNil)
+		e, err := b.Build("format")
+		So(err, ShouldBeNil)
+		fe := NewEngine(e, WithLuhn(Crockford32))
+		s := fe.MustNextString()
+		id, err := fe.Parse(s)
+		So(err, ShouldBeNil)
+		So(id, ShouldEqual, 30000001)
+		s, err = fe.NextString(context.Background())
+		So(err, ShouldBeNil)
+		id, err = fe.Parse(s)
+		So(err, ShouldBeNil)
+		So(id, ShouldEqual, 30000002)
+		So(func() { panicIfErr(ErrEmpty) }, ShouldPanicWith, "format: "+ErrEmpty.Error())
+		So(func() { panicIfErr(nil) }, ShouldNotPanic)
+	})
+}
@@ -17,11 +17,24 @@ func OptionsOptionDeclareWithDefault() interface{} {
 		"SegmentDuration":            time.Duration(900 * time.Second),     // @MethodComment(设定segment长度，renew号段尺寸调节的目的是使号段消耗稳定趋于SegmentDuration内。降低SegmentDuration，可以更迅速使缓存的号段达到设定的最大数值以提高吞吐能力)
 		"MinQuantum":                 uint64(30),                           // @MethodComment(根据renew请求频率自动伸缩的请求id缓存段，最小段长)
 		"MaxQuantum":                 uint64(3000),                         // @MethodComment(最大段长)
-		"InitialQuantum":             uint64(30),                           // @MethodComment(初始化段长)
+		"InitialQuantum":             uint64(30),                           // @MethodComment(首个号段的段长，限制在[MinQuantum, MaxQuantum]内)
 		"EnableSlow":                 true,                                 // @MethodComment(是否开启慢日志)
 		"SlowQuery":                  time.Duration(30 * time.Millisecond), // @MethodComment(慢日志最小时长，大于该时长将输出日志)
 		"EnableTimeSummary":          false,                                // @MethodComment(是否开启Next/MustNext接口的time监控，否则为统计监控)
 		"Development":                true,                                 // @MethodComment(是否为开发模式)
 		"EnableMonitor":              true,                                 // @MethodComment(是否开启监控)
+		"SegmentJournal":             "",                                   // @MethodComment(本地号段日志文件路径，非空时renew及号段切换后落盘，重启时优先使用日志中未使用的号段)
+		"EnableBuiltinMiddlewares":   true,                                 // @MethodComment(是否启用内置的Driver中间件，依次为重试(RenewRetry、RenewRetryDelay)、panic恢复、超时(RenewTimeout))
+		// annotation@Middlewares(xconf="-")
+		"Middlewares":             []DriverMiddleware(nil),        // @MethodComment(自定义的Driver中间件，位于内置中间件的外层)
+		"CircuitBreakerThreshold": uint(0),                        // @MethodComment(熔断阈值，连续renew失败达到该次数后熔断，熔断期间号段耗尽时Next直接返回ErrRenewCircuitOpen，0为不启用)
+		"CircuitBreakerCoolDown":  time.Duration(5 * time.Second), // @MethodComment(熔断冷却时长，熔断后经过该时长进入半开状态，允许一次renew探测，成功则恢复)
+		"RegionCount":             uint64(0),                      // @MethodComment(多区域模式的区域数量，大于1时各区域的id互不重叠：id = 号段序号*RegionCount + RegionIndex，0或1为不启用)
+		"RegionIndex":             uint64(0),                      // @MethodComment(多区域模式下本区域的序号，取值[0, RegionCount)，各区域须使用相同的RegionCount及不同的RegionIndex)
+		// annotation@DomainOptions(xconf="-")
+		"DomainOptions":   map[string][]DomainOption(nil), // @MethodComment(domain级别的配置，覆盖本配置中的同名配置，BuildWithOptions指定的配置优先)
+		"EnableHotReload": false,                          // @MethodComment(是否通过AtomicOptions读取配置，以支持由xconf热更新配置，SegmentJournal、Middlewares、熔断及多区域配置仍只在创建Builder时读取)
+		"ClampOptions":    false,                          // @MethodComment(配置不合法时修正为最接近的合法值并输出警告，而不是返回错误，无法修正的配置仍返回错误)
+		"WarmupOnBuild":   false,                          // @MethodComment(新建Engine时是否预取当前号段及下一号段，以RenewTimeout为限，避免首次Next同步等待renew)
 	}
 }
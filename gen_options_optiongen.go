@@ -21,12 +21,25 @@ type Options struct {
 	SegmentDuration            time.Duration `xconf:"segment_duration" usage:"设定segment长度，renew号段尺寸调节的目的是使号段消耗稳定趋于SegmentDuration内。降低SegmentDuration，可以更迅速使缓存的号段达到设定的最大数值以提高吞吐能力"`
 	MinQuantum                 uint64        `xconf:"min_quantum" usage:"根据renew请求频率自动伸缩的请求id缓存段，最小段长"`
 	MaxQuantum                 uint64        `xconf:"max_quantum" usage:"最大段长"`
-	InitialQuantum             uint64        `xconf:"initial_quantum" usage:"初始化段长"`
+	InitialQuantum             uint64        `xconf:"initial_quantum" usage:"首个号段的段长，限制在[MinQuantum, MaxQuantum]内"`
 	EnableSlow                 bool          `xconf:"enable_slow" usage:"是否开启慢日志"`
 	SlowQuery                  time.Duration `xconf:"slow_query" usage:"慢日志最小时长，大于该时长将输出日志"`
 	EnableTimeSummary          bool          `xconf:"enable_time_summary" usage:"是否开启Next/MustNext接口的time监控，否则为统计监控"`
 	Development                bool          `xconf:"development" usage:"是否为开发模式"`
 	EnableMonitor              bool          `xconf:"enable_monitor" usage:"是否开启监控"`
+	SegmentJournal             string        `xconf:"segment_journal" usage:"本地号段日志文件路径，非空时renew及号段切换后落盘，重启时优先使用日志中未使用的号段"`
+	EnableBuiltinMiddlewares   bool          `xconf:"enable_builtin_middlewares" usage:"是否启用内置的Driver中间件，依次为重试(RenewRetry、RenewRetryDelay)、panic恢复、超时(RenewTimeout)"`
+	// annotation@Middlewares(xconf="-")
+	Middlewares             []DriverMiddleware `xconf:"-" usage:"自定义的Driver中间件，位于内置中间件的外层"`
+	CircuitBreakerThreshold uint               `xconf:"circuit_breaker_threshold" usage:"熔断阈值，连续renew失败达到该次数后熔断，熔断期间号段耗尽时Next直接返回ErrRenewCircuitOpen，0为不启用"`
+	CircuitBreakerCoolDown  time.Duration      `xconf:"circuit_breaker_cool_down" usage:"熔断冷却时长，熔断后经过该时长进入半开状态，允许一次renew探测，成功则恢复"`
+	RegionCount             uint64             `xconf:"region_count" usage:"多区域模式的区域数量，大于1时各区域的id互不重叠：id = 号段序号*RegionCount + RegionIndex，0或1为不启用"`
+	RegionIndex             uint64             `xconf:"region_index" usage:"多区域模式下本区域的序号，取值[0, RegionCount)，各区域须使用相同的RegionCount及不同的RegionIndex"`
+	// annotation@DomainOptions(xconf="-")
+	DomainOptions   map[string][]DomainOption `xconf:"-" usage:"domain级别的配置，覆盖本配置中的同名配置，BuildWithOptions指定的配置优先"`
+	EnableHotReload bool                      `xconf:"enable_hot_reload" usage:"是否通过AtomicOptions读取配置，以支持由xconf热更新配置，SegmentJournal、Middlewares、熔断及多区域配置仍只在创建Builder时读取"`
+	ClampOptions    bool                      `xconf:"clamp_options" usage:"配置不合法时修正为最接近的合法值并输出警告，而不是返回错误，无法修正的配置仍返回错误"`
+	WarmupOnBuild   bool                      `xconf:"warmup_on_build" usage:"新建Engine时是否预取当前号段及下一号段，以RenewTimeout为限，避免首次Next同步等待renew"`
 }
 
 // NewConfig new Options
@@ -137,7 +150,7 @@ func WithMaxQuantum(v uint64) Option {
 	}
 }
 
-// WithInitialQuantum 初始化段长
+// WithInitialQuantum 首个号段的段长，限制在[MinQuantum, MaxQuantum]内
 func WithInitialQuantum(v uint64) Option {
 	return func(cc *Options) Option {
 		previous := cc.InitialQuantum
@@ -191,6 +204,105 @@ func WithEnableMonitor(v bool) Option {
 	}
 }
 
+// WithSegmentJournal 本地号段日志文件路径，非空时renew及号段切换后落盘，重启时优先使用日志中未使用的号段
+func WithSegmentJournal(v string) Option {
+	return func(cc *Options) Option {
+		previous := cc.SegmentJournal
+		cc.SegmentJournal = v
+		return WithSegmentJournal(previous)
+	}
+}
+
+// WithEnableBuiltinMiddlewares 是否启用内置的Driver中间件，依次为重试(RenewRetry、RenewRetryDelay)、panic恢复、超时(RenewTimeout)
+func WithEnableBuiltinMiddlewares(v bool) Option {
+	return func(cc *Options) Option {
+		previous := cc.EnableBuiltinMiddlewares
+		cc.EnableBuiltinMiddlewares = v
+		return WithEnableBuiltinMiddlewares(previous)
+	}
+}
+
+// WithMiddlewares 自定义的Driver中间件，位于内置中间件的外层
+func WithMiddlewares(v ...DriverMiddleware) Option {
+	return func(cc *Options) Option {
+		previous := cc.Middlewares
+		cc.Middlewares = v
+		return WithMiddlewares(previous...)
+	}
+}
+
+// WithCircuitBreakerThreshold 熔断阈值，连续renew失败达到该次数后熔断，熔断期间号段耗尽时Next直接返回ErrRenewCircuitOpen，0为不启用
+func WithCircuitBreakerThreshold(v uint) Option {
+	return func(cc *Options) Option {
+		previous := cc.CircuitBreakerThreshold
+		cc.CircuitBreakerThreshold = v
+		return WithCircuitBreakerThreshold(previous)
+	}
+}
+
+// WithCircuitBreakerCoolDown 熔断冷却时长，熔断后经过该时长进入半开状态，允许一次renew探测，成功则恢复
+func WithCircuitBreakerCoolDown(v time.Duration) Option {
+	return func(cc *Options) Option {
+		previous := cc.CircuitBreakerCoolDown
+		cc.CircuitBreakerCoolDown = v
+		return WithCircuitBreakerCoolDown(previous)
+	}
+}
+
+// WithRegionCount 多区域模式的区域数量，大于1时各区域的id互不重叠：id = 号段序号*RegionCount + RegionIndex，0或1为不启用
+func WithRegionCount(v uint64) Option {
+	return func(cc *Options) Option {
+		previous := cc.RegionCount
+		cc.RegionCount = v
+		return WithRegionCount(previous)
+	}
+}
+
+// WithRegionIndex 多区域模式下本区域的序号，取值[0, RegionCount)，各区域须使用相同的RegionCount及不同的RegionIndex
+func WithRegionIndex(v uint64) Option {
+	return func(cc *Options) Option {
+		previous := cc.RegionIndex
+		cc.RegionIndex = v
+		return WithRegionIndex(previous)
+	}
+}
+
+// WithDomainOptions domain级别的配置，覆盖本配置中的同名配置，BuildWithOptions指定的配置优先
+func WithDomainOptions(v map[string][]DomainOption) Option {
+	return func(cc *Options) Option {
+		previous := cc.DomainOptions
+		cc.DomainOptions = v
+		return WithDomainOptions(previous)
+	}
+}
+
+// WithEnableHotReload 是否通过AtomicOptions读取配置，以支持由xconf热更新配置，SegmentJournal、Middlewares、熔断及多区域配置仍只在创建Builder时读取
+func WithEnableHotReload(v bool) Option {
+	return func(cc *Options) Option {
+		previous := cc.EnableHotReload
+		cc.EnableHotReload = v
+		return WithEnableHotReload(previous)
+	}
+}
+
+// WithClampOptions 配置不合法时修正为最接近的合法值并输出警告，而不是返回错误，无法修正的配置仍返回错误
+func WithClampOptions(v bool) Option {
+	return func(cc *Options) Option {
+		previous := cc.ClampOptions
+		cc.ClampOptions = v
+		return WithClampOptions(previous)
+	}
+}
+
+// WithWarmupOnBuild 新建Engine时是否预取当前号段及下一号段，以RenewTimeout为限，避免首次Next同步等待renew
+func WithWarmupOnBuild(v bool) Option {
+	return func(cc *Options) Option {
+		previous := cc.WarmupOnBuild
+		cc.WarmupOnBuild = v
+		return WithWarmupOnBuild(previous)
+	}
+}
+
 // InstallOptionsWatchDog the installed func will called when NewConfig  called
 func InstallOptionsWatchDog(dog func(cc *Options)) { watchDogOptions = dog }
 
@@ -217,6 +329,17 @@ func newDefaultOptions() *Options {
 		WithEnableTimeSummary(false),
 		WithDevelopment(true),
 		WithEnableMonitor(true),
+		WithSegmentJournal(""),
+		WithEnableBuiltinMiddlewares(true),
+		WithMiddlewares(nil...),
+		WithCircuitBreakerThreshold(0),
+		WithCircuitBreakerCoolDown(5 * time.Second),
+		WithRegionCount(0),
+		WithRegionIndex(0),
+		WithDomainOptions(nil),
+		WithEnableHotReload(false),
+		WithClampOptions(false),
+		WithWarmupOnBuild(false),
 	} {
 		opt(cc)
 	}
@@ -264,21 +387,32 @@ func AtomicOptions() OptionsVisitor {
 }
 
 // all getter func
-func (cc *Options) GetLimitation() uint64                 { return cc.Limitation }
-func (cc *Options) GetOffsetWhenAutoCreateDomain() uint64 { return cc.OffsetWhenAutoCreateDomain }
-func (cc *Options) GetRenewPercent() int                  { return cc.RenewPercent }
-func (cc *Options) GetRenewTimeout() time.Duration        { return cc.RenewTimeout }
-func (cc *Options) GetRenewRetry() uint                   { return cc.RenewRetry }
-func (cc *Options) GetRenewRetryDelay() time.Duration     { return cc.RenewRetryDelay }
-func (cc *Options) GetSegmentDuration() time.Duration     { return cc.SegmentDuration }
-func (cc *Options) GetMinQuantum() uint64                 { return cc.MinQuantum }
-func (cc *Options) GetMaxQuantum() uint64                 { return cc.MaxQuantum }
-func (cc *Options) GetInitialQuantum() uint64             { return cc.InitialQuantum }
-func (cc *Options) GetEnableSlow() bool                   { return cc.EnableSlow }
-func (cc *Options) GetSlowQuery() time.Duration           { return cc.SlowQuery }
-func (cc *Options) GetEnableTimeSummary() bool            { return cc.EnableTimeSummary }
-func (cc *Options) GetDevelopment() bool                  { return cc.Development }
-func (cc *Options) GetEnableMonitor() bool                { return cc.EnableMonitor }
+func (cc *Options) GetLimitation() uint64                       { return cc.Limitation }
+func (cc *Options) GetOffsetWhenAutoCreateDomain() uint64       { return cc.OffsetWhenAutoCreateDomain }
+func (cc *Options) GetRenewPercent() int                        { return cc.RenewPercent }
+func (cc *Options) GetRenewTimeout() time.Duration              { return cc.RenewTimeout }
+func (cc *Options) GetRenewRetry() uint                         { return cc.RenewRetry }
+func (cc *Options) GetRenewRetryDelay() time.Duration           { return cc.RenewRetryDelay }
+func (cc *Options) GetSegmentDuration() time.Duration           { return cc.SegmentDuration }
+func (cc *Options) GetMinQuantum() uint64                       { return cc.MinQuantum }
+func (cc *Options) GetMaxQuantum() uint64                       { return cc.MaxQuantum }
+func (cc *Options) GetInitialQuantum() uint64                   { return cc.InitialQuantum }
+func (cc *Options) GetEnableSlow() bool                         { return cc.EnableSlow }
+func (cc *Options) GetSlowQuery() time.Duration                 { return cc.SlowQuery }
+func (cc *Options) GetEnableTimeSummary() bool                  { return cc.EnableTimeSummary }
+func (cc *Options) GetDevelopment() bool                        { return cc.Development }
+func (cc *Options) GetEnableMonitor() bool                      { return cc.EnableMonitor }
+func (cc *Options) GetSegmentJournal() string                   { return cc.SegmentJournal }
+func (cc *Options) GetEnableBuiltinMiddlewares() bool           { return cc.EnableBuiltinMiddlewares }
+func (cc *Options) GetMiddlewares() []DriverMiddleware          { return cc.Middlewares }
+func (cc *Options) GetCircuitBreakerThreshold() uint            { return cc.CircuitBreakerThreshold }
+func (cc *Options) GetCircuitBreakerCoolDown() time.Duration    { return cc.CircuitBreakerCoolDown }
+func (cc *Options) GetRegionCount() uint64                      { return cc.RegionCount }
+func (cc *Options) GetRegionIndex() uint64                      { return cc.RegionIndex }
+func (cc *Options) GetDomainOptions() map[string][]DomainOption { return cc.DomainOptions }
+func (cc *Options) GetEnableHotReload() bool                    { return cc.EnableHotReload }
+func (cc *Options) GetClampOptions() bool                       { return cc.ClampOptions }
+func (cc *Options) GetWarmupOnBuild() bool                      { return cc.WarmupOnBuild }
 
 // OptionsVisitor visitor interface for Options
 type OptionsVisitor interface {
@@ -297,6 +431,17 @@ type OptionsVisitor interface {
 	GetEnableTimeSummary() bool
 	GetDevelopment() bool
 	GetEnableMonitor() bool
+	GetSegmentJournal() string
+	GetEnableBuiltinMiddlewares() bool
+	GetMiddlewares() []DriverMiddleware
+	GetCircuitBreakerThreshold() uint
+	GetCircuitBreakerCoolDown() time.Duration
+	GetRegionCount() uint64
+	GetRegionIndex() uint64
+	GetDomainOptions() map[string][]DomainOption
+	GetEnableHotReload() bool
+	GetClampOptions() bool
+	GetWarmupOnBuild() bool
 }
 
 // OptionsInterface visitor + ApplyOption interface for Options
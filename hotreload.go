@@ -0,0 +1,76 @@
+package siid
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandwich-go/logbus"
+)
+
+// ErrHotReloadBuilderExists AtomicOptions为全局配置，同一时间只允许一个热更新模式的Builder
+var ErrHotReloadBuilderExists = errors.New("another hot reload builder is in use")
+
+// hotReloadBound 是否已有热更新模式的Builder完成Prepare，Destroy时释放
+var hotReloadBound int32
+
+func bindHotReload() error {
+	if !atomic.CompareAndSwapInt32(&hotReloadBound, 0, 1) {
+		return ErrHotReloadBuilderExists
+	}
+	return nil
+}
+
+func unbindHotReload() { atomic.StoreInt32(&hotReloadBound, 0) }
+
+func init() {
+	InstallCallbackOnAtomicOptionsSet(func(cc OptionsInterface) bool {
+		if cc.GetClampOptions() {
+			clampOptions(cc)
+		}
+		if err := validateOptions(cc); err != nil {
+			logbus.Warn(w("reject options update"), logbus.ErrorField(err))
+			return false
+		}
+		return true
+	})
+}
+
+// hotReloadVisitor 热更新模式下Builder使用的OptionsVisitor
+// 可热更新的配置每次读取AtomicOptions，其余配置读取创建Builder时的Options
+type hotReloadVisitor struct {
+	OptionsVisitor
+}
+
+// newHotReloadVisitor 若AtomicOptions尚未设置，以opts作为初始配置
+func newHotReloadVisitor(opts *Options) OptionsVisitor {
+	if atomic.LoadPointer(&atomicOptions) == nil {
+		AtomicOptionsSet(opts)
+	}
+	return hotReloadVisitor{OptionsVisitor: opts}
+}
+
+func (hotReloadVisitor) GetLimitation() uint64 { return AtomicOptions().GetLimitation() }
+func (hotReloadVisitor) GetOffsetWhenAutoCreateDomain() uint64 {
+	return AtomicOptions().GetOffsetWhenAutoCreateDomain()
+}
+func (hotReloadVisitor) GetRenewPercent() int           { return AtomicOptions().GetRenewPercent() }
+func (hotReloadVisitor) GetRenewTimeout() time.Duration { return AtomicOptions().GetRenewTimeout() }
+func (hotReloadVisitor) GetRenewRetry() uint            { return AtomicOptions().GetRenewRetry() }
+func (hotReloadVisitor) GetRenewRetryDelay() time.Duration {
+	return AtomicOptions().GetRenewRetryDelay()
+}
+func (hotReloadVisitor) GetSegmentDuration() time.Duration {
+	return AtomicOptions().GetSegmentDuration()
+}
+func (hotReloadVisitor) GetMinQuantum() uint64       { return AtomicOptions().GetMinQuantum() }
+func (hotReloadVisitor) GetMaxQuantum() uint64       { return AtomicOptions().GetMaxQuantum() }
+func (hotReloadVisitor) GetInitialQuantum() uint64   { return AtomicOptions().GetInitialQuantum() }
+func (hotReloadVisitor) GetEnableSlow() bool         { return AtomicOptions().GetEnableSlow() }
+func (hotReloadVisitor) GetSlowQuery() time.Duration { return AtomicOptions().GetSlowQuery() }
+func (hotReloadVisitor) GetEnableTimeSummary() bool  { return AtomicOptions().GetEnableTimeSummary() }
+func (hotReloadVisitor) GetDevelopment() bool        { return AtomicOptions().GetDevelopment() }
+func (hotReloadVisitor) GetEnableMonitor() bool      { return AtomicOptions().GetEnableMonitor() }
+func (hotReloadVisitor) GetDomainOptions() map[string][]DomainOption {
+	return AtomicOptions().GetDomainOptions()
+}
@@ -0,0 +1,65 @@
+package siid
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHotReload(t *testing.T) {
+	Convey("hot reload", t, func() {
+		atomic.StorePointer(&atomicOptions, nil)
+		defer atomic.StorePointer(&atomicOptions, nil)
+
+		Convey("disabled by default", func() {
+			b := NewWithDriver(getDummyDriver(), NewConfig(WithOffsetWhenAutoCreateDomain(0), WithMinQuantum(10), WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			AtomicOptionsSet(NewConfig(WithMinQuantum(50), WithMaxQuantum(50)))
+			e, _ := b.Build("player")
+			So(e.MustNext(), ShouldEqual, 1)
+			So(e.Stats().Max, ShouldEqual, 30)
+		})
+
+		Convey("reload", func() {
+			opts := NewConfig(WithEnableHotReload(true), WithOffsetWhenAutoCreateDomain(0),
+				WithMinQuantum(10), WithMaxQuantum(10), WithRenewPercent(100), WithDevelopment(false))
+			b := NewWithDriver(getDummyDriver(), opts)
+			So(AtomicOptions(), ShouldEqual, opts)
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			Reset(func() { _ = b.Destroy(context.Background()) })
+			e, _ := b.Build("player")
+			So(e.MustNext(), ShouldEqual, 1)
+			So(e.Stats().Max, ShouldEqual, 10)
+
+			AtomicOptionsSet(NewConfig(WithOffsetWhenAutoCreateDomain(0), WithMinQuantum(50), WithMaxQuantum(50),
+				WithRenewPercent(100), WithLimitation(30), WithDevelopment(false)))
+			// 耗尽当前号段，新号段按更新后的配置申请
+			for i := 2; i <= 10; i++ {
+				So(e.MustNext(), ShouldEqual, i)
+			}
+			So(e.MustNext(), ShouldEqual, 11)
+			So(e.Stats().Max, ShouldEqual, 60)
+			for i := 12; i <= 30; i++ {
+				So(e.MustNext(), ShouldEqual, i)
+			}
+			_, err := e.Next()
+			So(err, ShouldEqual, ErrReachIdLimitation)
+
+			Convey("reject second builder", func() {
+				other := NewWithDriver(getDummyDriver(), NewConfig(WithEnableHotReload(true), WithDevelopment(false)))
+				So(other.Prepare(context.Background()), ShouldEqual, ErrHotReloadBuilderExists)
+				So(b.Destroy(context.Background()), ShouldBeNil)
+				So(other.Prepare(context.Background()), ShouldBeNil)
+				So(other.Destroy(context.Background()), ShouldBeNil)
+			})
+
+			Convey("invalid update rejected", func() {
+				current := AtomicOptions()
+				AtomicOptionsSet(NewConfig(WithMinQuantum(100), WithMaxQuantum(10)))
+				So(AtomicOptions(), ShouldEqual, current)
+			})
+		})
+	})
+}
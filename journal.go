@@ -0,0 +1,200 @@
+package siid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gofrs/flock"
+	"github.com/sandwich-go/logbus"
+)
+
+var ErrJournalLocked = errors.New("segment journal is locked by another process")
+
+// journalSegment 号段日志中一个domain的号段
+type journalSegment struct {
+	Domain  string `json:"domain"`
+	N       uint64 `json:"n"`
+	Max     uint64 `json:"max"`
+	NextN   uint64 `json:"next_n"`
+	NextMax uint64 `json:"next_max"`
+}
+
+type journalContent struct {
+	// Clean 是否为正常关闭时写入，只有正常关闭时记录的当前号段n才是准确的
+	Clean    bool              `json:"clean"`
+	Segments []*journalSegment `json:"segments"`
+}
+
+// journal 本地号段日志
+// 每次renew及号段切换后将号段写入文件并fsync，重启时engine优先使用日志中未使用的号段
+// 非正常关闭时，当前号段中已使用到何处无法确定，仅恢复完全未使用的下一号段
+type journal struct {
+	path     string
+	lock     *flock.Flock
+	mu       sync.Mutex
+	segments map[string]*journalSegment
+	broken   bool // 写入失败后不再使用日志
+}
+
+func openJournal(path string) (*journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	lock := flock.New(path + ".lock")
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("%w: %s", ErrJournalLocked, path)
+	}
+	j := &journal{path: path, lock: lock, segments: make(map[string]*journalSegment)}
+	if err = j.load(); err == nil {
+		// 立即去掉正常关闭标记，此后当前号段的n不再准确
+		err = j.write(false)
+	}
+	if err != nil {
+		_ = lock.Unlock()
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *journal) load() error {
+	data, err := ioutil.ReadFile(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var content journalContent
+	if err = json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("parse segment journal %s: %w", j.path, err)
+	}
+	for _, s := range content.Segments {
+		if !content.Clean || s.Max <= s.N {
+			// 当前号段的使用情况未知或已用完，丢弃，下一号段作为当前号段
+			s.N, s.Max, s.NextN, s.NextMax = s.NextN, s.NextMax, 0, 0
+		}
+		if s.Max > s.N {
+			j.segments[s.Domain] = s
+		}
+	}
+	return nil
+}
+
+// take 获取domain在日志中的号段，此后由engine负责更新
+func (j *journal) take(domain string) (journalSegment, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s, ok := j.segments[domain]
+	if !ok {
+		return journalSegment{}, false
+	}
+	return *s, true
+}
+
+func (j *journal) segment(domain string) *journalSegment {
+	s, ok := j.segments[domain]
+	if !ok {
+		s = &journalSegment{Domain: domain}
+		j.segments[domain] = s
+	}
+	return s
+}
+
+// saveNext renew后记录下一号段
+func (j *journal) saveNext(domain string, nextN, nextMax uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := j.segment(domain)
+	s.NextN, s.NextMax = nextN, nextMax
+	j.flush()
+}
+
+// saveCurrent 号段切换时记录当前号段，须在号段投入使用前调用，否则异常退出后下一号段可能被重复使用
+func (j *journal) saveCurrent(domain string, n, max uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := j.segment(domain)
+	s.N, s.Max, s.NextN, s.NextMax = n, max, 0, 0
+	j.flush()
+}
+
+// flush 写入日志，失败时删除日志文件并不再使用，避免重启后使用过期的号段
+func (j *journal) flush() {
+	if j.broken {
+		return
+	}
+	if err := j.write(false); err != nil {
+		j.broken = true
+		logbus.Error(w("write segment journal failed"), logbus.ErrorField(err), logbus.String("path", j.path))
+		if err0 := os.Remove(j.path); err0 != nil && !os.IsNotExist(err0) {
+			logbus.Error(w("remove segment journal failed"), logbus.ErrorField(err0), logbus.String("path", j.path))
+		}
+	}
+}
+
+// put 关闭时记录engine的剩余号段，只更新内存，由close统一写入
+func (j *journal) put(s journalSegment) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if s.Max <= s.N && s.NextMax <= s.NextN {
+		delete(j.segments, s.Domain)
+		return
+	}
+	j.segments[s.Domain] = &s
+}
+
+// remove 关闭时无法确定engine的剩余号段，不再记录该domain
+func (j *journal) remove(domain string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.segments, domain)
+}
+
+// close 以正常关闭标记写入日志，并释放文件锁
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var err error
+	if !j.broken {
+		err = j.write(true)
+	}
+	if err0 := j.lock.Unlock(); err == nil {
+		err = err0
+	}
+	return err
+}
+
+func (j *journal) write(clean bool) error {
+	content := journalContent{Clean: clean, Segments: make([]*journalSegment, 0, len(j.segments))}
+	for _, s := range j.segments {
+		content.Segments = append(content.Segments, s)
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if err0 := f.Close(); err == nil {
+		err = err0
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
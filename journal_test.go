@@ -0,0 +1,101 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// noReturnDriver 屏蔽SegmentReturner，使号段只能通过日志恢复
+type noReturnDriver struct{ Driver }
+
+// failPrepareDriver 前fails次Prepare返回错误
+type failPrepareDriver struct {
+	Driver
+	fails int
+}
+
+func (d *failPrepareDriver) Prepare(ctx context.Context) error {
+	if d.fails > 0 {
+		d.fails--
+		return errors.New("prepare failed")
+	}
+	return d.Driver.Prepare(ctx)
+}
+
+func TestSegmentJournal(t *testing.T) {
+	Convey("segment journal", t, func() {
+		path := filepath.Join(t.TempDir(), "siid.journal")
+		driver := getDummyDriver()
+		newBuilder := func() Builder {
+			b := NewWithDriver(&noReturnDriver{Driver: driver}, NewConfig(
+				WithOffsetWhenAutoCreateDomain(defaultOffsetWhenAutoCreateDomain),
+				WithSegmentJournal(path),
+				WithDevelopment(false)),
+			)
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			return b
+		}
+
+		Convey("should lock journal", func() {
+			b := newBuilder()
+			err := NewWithDriver(driver, NewConfig(WithSegmentJournal(path))).Prepare(context.Background())
+			So(errors.Is(err, ErrJournalLocked), ShouldBeTrue)
+			So(b.Destroy(context.Background()), ShouldBeNil)
+		})
+
+		Convey("should retry prepare after driver failure", func() {
+			b := NewWithDriver(&failPrepareDriver{Driver: driver, fails: 1}, NewConfig(WithSegmentJournal(path), WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldNotBeNil)
+			_, err := b.Build("journal")
+			So(err, ShouldEqual, ErrorDriverHasNotInited)
+			// 失败时释放了日志文件锁，重试可以成功
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			_, err = b.Build("journal")
+			So(err, ShouldBeNil)
+			So(b.Destroy(context.Background()), ShouldBeNil)
+		})
+
+		Convey("should resume after destroy", func() {
+			b := newBuilder()
+			e, _ := b.Build("journal")
+			var id uint64
+			var err error
+			for i := 0; i < 5; i++ {
+				id, err = e.Next()
+				So(err, ShouldBeNil)
+			}
+			So(b.Destroy(context.Background()), ShouldBeNil)
+
+			b = newBuilder()
+			e, _ = b.Build("journal")
+			next, err := e.Next()
+			So(err, ShouldBeNil)
+			So(next, ShouldEqual, id+1)
+			So(b.Destroy(context.Background()), ShouldBeNil)
+		})
+
+		Convey("should not reuse current segment after crash", func() {
+			b := newBuilder()
+			e, _ := b.Build("journal")
+			var id uint64
+			var err error
+			for i := 0; i < 5; i++ {
+				id, err = e.Next()
+				So(err, ShouldBeNil)
+			}
+			// 模拟异常退出，不写入正常关闭标记
+			So(b.(*builder).journal.lock.Unlock(), ShouldBeNil)
+
+			b = newBuilder()
+			e, _ = b.Build("journal")
+			next, err := e.Next()
+			So(err, ShouldBeNil)
+			So(next, ShouldBeGreaterThan, id)
+			So(b.Destroy(context.Background()), ShouldBeNil)
+		})
+	})
+}
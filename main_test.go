@@ -0,0 +1,29 @@
+package siid
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+// packageCleanups 包内所有测试结束后执行，用于释放多个测试共享、按需启动的资源，如embedded postgres
+var (
+	packageCleanupsMu sync.Mutex
+	packageCleanups   []func()
+)
+
+func addPackageCleanup(f func()) {
+	packageCleanupsMu.Lock()
+	defer packageCleanupsMu.Unlock()
+	packageCleanups = append(packageCleanups, f)
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	packageCleanupsMu.Lock()
+	for i := len(packageCleanups) - 1; i >= 0; i-- {
+		packageCleanups[i]()
+	}
+	packageCleanupsMu.Unlock()
+	os.Exit(code)
+}
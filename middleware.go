@@ -0,0 +1,115 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sandwich-go/boost/retry"
+	"github.com/sandwich-go/logbus"
+)
+
+// DriverMiddleware Driver中间件，在Driver.Renew前后插入逻辑，例如tracing、限流、审计、故障注入
+type DriverMiddleware func(Driver) Driver
+
+// RenewFunc Driver.Renew的函数形式
+type RenewFunc func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error)
+
+// Chain 将中间件依次包装在driver外层，mws[0]位于最外层
+func Chain(driver Driver, mws ...DriverMiddleware) Driver {
+	for i := len(mws) - 1; i >= 0; i-- {
+		driver = mws[i](driver)
+	}
+	return driver
+}
+
+// RenewMiddleware 由包装Renew的函数生成中间件，Prepare、Destroy及SegmentReturner等转发给内层Driver
+func RenewMiddleware(wrap func(next RenewFunc) RenewFunc) DriverMiddleware {
+	return func(next Driver) Driver {
+		d := &renewDriver{Driver: next, renew: wrap(next.Renew)}
+		if returner, ok := next.(SegmentReturner); ok {
+			return &renewReturnerDriver{renewDriver: d, SegmentReturner: returner}
+		}
+		return d
+	}
+}
+
+type renewDriver struct {
+	Driver
+	renew RenewFunc
+}
+
+func (d *renewDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	return d.renew(ctx, domain, quantum, offsetOnCreate)
+}
+
+type renewReturnerDriver struct {
+	*renewDriver
+	SegmentReturner
+}
+
+// RecoverMiddleware 将Renew中的panic转换为错误
+func RecoverMiddleware() DriverMiddleware {
+	return RenewMiddleware(func(next RenewFunc) RenewFunc {
+		return func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (c uint64, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic %v", r)
+					logbus.Error(w("renew panic"), logbus.String("domain", domain), logbus.Any("recover", r))
+				}
+			}()
+			return next(ctx, domain, quantum, offsetOnCreate)
+		}
+	})
+}
+
+// TimeoutMiddleware 每次Renew的超时，与ctx的截止时间取较早者
+func TimeoutMiddleware(timeout time.Duration) DriverMiddleware {
+	return timeoutMiddleware(func() time.Duration { return timeout })
+}
+
+// timeoutMiddleware 每次Renew时读取超时，支持热更新
+func timeoutMiddleware(timeout func() time.Duration) DriverMiddleware {
+	return RenewMiddleware(func(next RenewFunc) RenewFunc {
+		return func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout())
+			defer cancel()
+			return next(ctx, domain, quantum, offsetOnCreate)
+		}
+	})
+}
+
+// RetryMiddleware Renew失败时重试，最多尝试limit次，第n次重试前等待n*delay，ctx结束或熔断时不再重试
+func RetryMiddleware(limit uint, delay time.Duration) DriverMiddleware {
+	return retryMiddleware(func() uint { return limit }, func() time.Duration { return delay })
+}
+
+// retryMiddleware 每次Renew时读取重试次数及延迟，支持热更新
+func retryMiddleware(limitFunc func() uint, delayFunc func() time.Duration) DriverMiddleware {
+	return RenewMiddleware(func(next RenewFunc) RenewFunc {
+		return func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (c uint64, err error) {
+			limit, delay := limitFunc(), delayFunc()
+			err = retry.Do(func(uint) (errRetry error) {
+				c, errRetry = next(ctx, domain, quantum, offsetOnCreate)
+				return errRetry
+			},
+				retry.WithContext(ctx),
+				retry.WithRetryIf(func(err error) bool { return !errors.Is(err, ErrRenewCircuitOpen) }),
+				retry.WithLimit(limit),
+				retry.WithDelayType(func(n uint, _ error, _ *retry.Options) time.Duration {
+					return time.Duration(n) * delay
+				}))
+			return c, err
+		}
+	})
+}
+
+// builtinMiddlewares 内置中间件：重试、熔断（breaker非nil时）、panic恢复、超时，由外至内
+func builtinMiddlewares(visitor OptionsVisitor, breaker *circuitBreaker) []DriverMiddleware {
+	mws := []DriverMiddleware{retryMiddleware(visitor.GetRenewRetry, visitor.GetRenewRetryDelay)}
+	if breaker != nil {
+		mws = append(mws, breaker.middleware())
+	}
+	return append(mws, RecoverMiddleware(), timeoutMiddleware(visitor.GetRenewTimeout))
+}
@@ -0,0 +1,107 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// flakyDriver 前fails次Renew返回错误或panic
+type flakyDriver struct {
+	Driver
+	fails int
+	panic bool
+	calls int
+}
+
+func (d *flakyDriver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	d.calls++
+	if d.calls <= d.fails {
+		if d.panic {
+			panic("flaky")
+		}
+		return 0, errors.New("flaky")
+	}
+	return d.Driver.Renew(ctx, domain, quantum, offsetOnCreate)
+}
+
+func (d *flakyDriver) ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error) {
+	return d.Driver.(SegmentReturner).ReturnSegment(ctx, domain, n, max)
+}
+
+func TestChain(t *testing.T) {
+	Convey("chain", t, func() {
+		var order []string
+		named := func(name string) DriverMiddleware {
+			return RenewMiddleware(func(next RenewFunc) RenewFunc {
+				return func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+					order = append(order, name)
+					return next(ctx, domain, quantum, offsetOnCreate)
+				}
+			})
+		}
+		d := Chain(getDummyDriver(), named("a"), named("b"))
+		_, err := d.Renew(context.Background(), "chain", 10, 0)
+		So(err, ShouldBeNil)
+		So(order, ShouldResemble, []string{"a", "b"})
+
+		_, ok := d.(SegmentReturner)
+		So(ok, ShouldBeTrue)
+		_, ok = Chain(&noReturnDriver{Driver: getDummyDriver()}, named("a")).(SegmentReturner)
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestBuiltinMiddlewares(t *testing.T) {
+	Convey("builtin middlewares", t, func() {
+		Convey("retry and recover", func() {
+			d := &flakyDriver{Driver: getDummyDriver(), fails: 2, panic: true}
+			_, err := Chain(d, RetryMiddleware(3, time.Millisecond), RecoverMiddleware()).Renew(context.Background(), "retry", 10, 0)
+			So(err, ShouldBeNil)
+			So(d.calls, ShouldEqual, 3)
+
+			d = &flakyDriver{Driver: getDummyDriver(), fails: 5}
+			_, err = Chain(d, RetryMiddleware(3, time.Millisecond)).Renew(context.Background(), "retry", 10, 0)
+			So(err, ShouldNotBeNil)
+			So(d.calls, ShouldEqual, 3)
+		})
+
+		Convey("timeout", func() {
+			_, err := Chain(&blockDriver{Driver: getDummyDriver()}, TimeoutMiddleware(10*time.Millisecond)).
+				Renew(context.Background(), "timeout", 10, 0)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+
+		Convey("options", func() {
+			var calls int
+			counter := RenewMiddleware(func(next RenewFunc) RenewFunc {
+				return func(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+					calls++
+					return next(ctx, domain, quantum, offsetOnCreate)
+				}
+			})
+			d := &flakyDriver{Driver: getDummyDriver(), fails: 1}
+			b := NewWithDriver(d, NewConfig(WithMiddlewares(counter), WithRenewRetryDelay(time.Millisecond), WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			e, _ := b.Build("options")
+			_, err := e.Next()
+			So(err, ShouldBeNil)
+			So(calls, ShouldEqual, 1)
+			So(d.calls, ShouldEqual, 2)
+
+			// 不启用内置中间件时renew失败不重试
+			d = &flakyDriver{Driver: getDummyDriver(), fails: 1}
+			b = NewWithDriver(d, NewConfig(WithEnableBuiltinMiddlewares(false), WithRenewRetry(1), WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			e, _ = b.Build("options")
+			_, err = e.Next()
+			So(err, ShouldEqual, ErrIdRunOut)
+			So(d.calls, ShouldEqual, 1)
+			_, err = e.Next()
+			So(err, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package siid
+
+import "context"
+
+// mutex 互斥锁，等待加锁时可因context结束而放弃
+// 加锁与解锁可以在不同的goroutine中进行，用于将renew交由新的goroutine完成
+type mutex chan struct{}
+
+func newMutex() mutex { return make(mutex, 1) }
+
+func (m mutex) Lock() { m <- struct{}{} }
+
+func (m mutex) Unlock() { <-m }
+
+// LockContext 加锁，若ctx先结束则放弃加锁，返回ctx.Err()
+func (m mutex) LockContext(ctx context.Context) error {
+	select {
+	case m <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryLock 尝试加锁，锁已被占用时立即返回false
+func (m mutex) TryLock() bool {
+	select {
+	case m <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,52 @@
+package siid
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidRegion = errors.New("invalid region")
+
+// 多区域模式
+// 各区域使用各自的Driver分配号段，号段中的序号n映射为id：n*RegionCount + RegionIndex
+// 即本区域的id均满足 id % RegionCount == RegionIndex，区域之间无需跨区域写入即可保证id唯一
+
+func regionEnabled(visitor OptionsVisitor) bool { return visitor.GetRegionCount() > 1 }
+
+// checkRegion 校验区域配置，拒绝可能与其他区域重叠的配置
+func checkRegion(visitor OptionsVisitor) error {
+	count, index := visitor.GetRegionCount(), visitor.GetRegionIndex()
+	if count <= 1 {
+		if index != 0 {
+			return fmt.Errorf("%w: RegionIndex %d requires RegionCount > %d", ErrInvalidRegion, index, index)
+		}
+		return nil
+	}
+	if index >= count {
+		return fmt.Errorf("%w: RegionIndex %d out of range [0, %d)", ErrInvalidRegion, index, count)
+	}
+	if visitor.GetLimitation() < index {
+		return fmt.Errorf("%w: Limitation %d less than RegionIndex %d", ErrInvalidRegion, visitor.GetLimitation(), index)
+	}
+	return nil
+}
+
+// regionLimitation 号段序号的上限，映射后的id不超过Limitation
+func regionLimitation(visitor OptionsVisitor) uint64 {
+	if !regionEnabled(visitor) {
+		return visitor.GetLimitation()
+	}
+	// 热更新后Limitation可能小于RegionIndex
+	if visitor.GetLimitation() < visitor.GetRegionIndex() {
+		return 0
+	}
+	return (visitor.GetLimitation() - visitor.GetRegionIndex()) / visitor.GetRegionCount()
+}
+
+// regionID 将号段序号映射为id，调用方须保证n不超过regionLimitation
+func regionID(visitor OptionsVisitor, n uint64) uint64 {
+	if !regionEnabled(visitor) {
+		return n
+	}
+	return n*visitor.GetRegionCount() + visitor.GetRegionIndex()
+}
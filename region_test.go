@@ -0,0 +1,61 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegion(t *testing.T) {
+	Convey("region", t, func() {
+		Convey("invalid config", func() {
+			for _, opts := range [][]Option{
+				{WithRegionCount(3), WithRegionIndex(3)},
+				{WithRegionCount(1), WithRegionIndex(1)},
+				{WithRegionIndex(2)},
+				{WithRegionCount(3), WithRegionIndex(2), WithLimitation(1)},
+			} {
+				err := NewWithDriver(getDummyDriver(), NewConfig(opts...)).Prepare(context.Background())
+				So(errors.Is(err, ErrInvalidRegion), ShouldBeTrue)
+			}
+		})
+
+		Convey("interleaved ids", func() {
+			const count = 3
+			seen := make(map[uint64]bool)
+			for index := uint64(0); index < count; index++ {
+				// 每个区域使用各自的Driver
+				b := NewWithDriver(getDummyDriver(), NewConfig(WithRegionCount(count), WithRegionIndex(index), WithDevelopment(false)))
+				So(b.Prepare(context.Background()), ShouldBeNil)
+				e, _ := b.Build("region")
+				for i := 0; i < 1000; i++ {
+					id, err := e.Next()
+					So(err, ShouldBeNil)
+					So(id%count, ShouldEqual, index)
+					So(seen[id], ShouldBeFalse)
+					seen[id] = true
+				}
+				// 本区域的id不连续，不能以区间返回，且不消耗id
+				current := e.Stats().Current
+				_, _, err := e.NextRange(context.Background(), 10)
+				So(err, ShouldEqual, ErrRangeNotContiguous)
+				So(e.Stats().Current, ShouldEqual, current)
+				So(e.Stats().Current%count, ShouldEqual, index)
+				So(b.Destroy(context.Background()), ShouldBeNil)
+			}
+		})
+
+		Convey("limitation", func() {
+			b := NewWithDriver(getDummyDriver(), NewConfig(WithRegionCount(4), WithRegionIndex(1),
+				WithOffsetWhenAutoCreateDomain(0), WithLimitation(9), WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			e, _ := b.Build("region")
+			So(e.MustNext(), ShouldEqual, 5)
+			So(e.MustNext(), ShouldEqual, 9)
+			_, err := e.Next()
+			So(err, ShouldEqual, ErrReachIdLimitation)
+		})
+	})
+}
@@ -0,0 +1,189 @@
+package siid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// siid服务的HTTP/JSON协议，id以字符串表示，避免其他语言（如JavaScript）丢失精度
+const (
+	RemotePathPing  = "/v1/ping"
+	RemotePathRenew = "/v1/renew"
+	RemotePathNext  = "/v1/next"
+)
+
+// RemoteRenewRequest 申请号段，对应Driver.Renew
+type RemoteRenewRequest struct {
+	Domain         string `json:"domain"`
+	Quantum        uint64 `json:"quantum,string"`
+	OffsetOnCreate uint64 `json:"offset_on_create,string"`
+}
+
+// RemoteRenewResponse 返回号段的当前id，号段为(Current, Current+Quantum]
+type RemoteRenewResponse struct {
+	Current uint64 `json:"current,string"`
+}
+
+// RemoteNextRequest 取N个连续的id，N为0时取1个
+type RemoteNextRequest struct {
+	Domain string `json:"domain"`
+	N      uint64 `json:"n,string"`
+}
+
+// RemoteNextResponse 返回连续的id [First, Last]
+type RemoteNextResponse struct {
+	First uint64 `json:"first,string"`
+	Last  uint64 `json:"last,string"`
+}
+
+var (
+	ErrRemoteEmptyDomain    = errors.New("empty domain")
+	ErrRemoteInvalidQuantum = errors.New("invalid quantum")
+)
+
+// RemoteError 错误响应
+type RemoteError struct {
+	Error string `json:"error"`
+}
+
+// RemoteServer siid服务的实现，Renew经由Builder包装了中间件的Driver转发，Next由服务端的Builder缓存号段
+// 供HTTP、gRPC等传输层共用
+type RemoteServer struct {
+	builder *builder
+}
+
+// NewRemoteServer 新建siid服务
+func NewRemoteServer(driver Driver, opts *Options) *RemoteServer {
+	return NewRemoteServerWithBuilder(NewWithDriver(driver, opts))
+}
+
+// NewRemoteServerWithBuilder 基于New或NewWithDriver返回的Builder新建siid服务，可通过New使用Register注册的driver
+func NewRemoteServerWithBuilder(b Builder) *RemoteServer {
+	bb, ok := b.(*builder)
+	if !ok {
+		panicIfErr(fmt.Errorf("remote server requires builder created by New or NewWithDriver, got %T", b))
+	}
+	return &RemoteServer{builder: bb}
+}
+
+// Prepare 调用Builder.Prepare
+func (s *RemoteServer) Prepare(ctx context.Context) error { return s.builder.Prepare(ctx) }
+
+// Destroy 调用Builder.Destroy
+func (s *RemoteServer) Destroy(ctx context.Context) error { return s.builder.Destroy(ctx) }
+
+func (s *RemoteServer) Renew(ctx context.Context, req *RemoteRenewRequest) (*RemoteRenewResponse, error) {
+	if req.Domain == "" {
+		return nil, ErrRemoteEmptyDomain
+	}
+	// 限制单次申请的段长，避免异常客户端耗尽domain的id空间
+	if max := s.builder.visitor.GetMaxQuantum(); req.Quantum == 0 || req.Quantum > max {
+		return nil, fmt.Errorf("%w: %d out of range [1, %d]", ErrRemoteInvalidQuantum, req.Quantum, max)
+	}
+	if err := s.builder.checkAvailableFlag(); err != nil {
+		return nil, err
+	}
+	c, err := s.builder.driver.Renew(ctx, req.Domain, req.Quantum, req.OffsetOnCreate)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteRenewResponse{Current: c}, nil
+}
+
+func (s *RemoteServer) Next(ctx context.Context, req *RemoteNextRequest) (*RemoteNextResponse, error) {
+	if req.Domain == "" {
+		return nil, ErrRemoteEmptyDomain
+	}
+	e, err := s.builder.Build(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if req.N <= 1 {
+		id, err0 := e.NextContext(ctx)
+		if err0 != nil {
+			return nil, err0
+		}
+		return &RemoteNextResponse{First: id, Last: id}, nil
+	}
+	first, last, err := e.NextRange(ctx, req.N)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteNextResponse{First: first, Last: last}, nil
+}
+
+// NewRemoteHandler 返回siid服务的HTTP/JSON handler
+func NewRemoteHandler(s *RemoteServer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(RemotePathPing, func(w http.ResponseWriter, r *http.Request) {
+		if err := s.builder.checkAvailableFlag(); err != nil {
+			writeRemoteError(w, err)
+			return
+		}
+		writeRemoteJSON(w, http.StatusOK, struct{}{})
+	})
+	mux.HandleFunc(RemotePathRenew, func(w http.ResponseWriter, r *http.Request) {
+		var req RemoteRenewRequest
+		if !readRemoteJSON(w, r, &req) {
+			return
+		}
+		if resp, err := s.Renew(r.Context(), &req); err != nil {
+			writeRemoteError(w, err)
+		} else {
+			writeRemoteJSON(w, http.StatusOK, resp)
+		}
+	})
+	mux.HandleFunc(RemotePathNext, func(w http.ResponseWriter, r *http.Request) {
+		var req RemoteNextRequest
+		if !readRemoteJSON(w, r, &req) {
+			return
+		}
+		if resp, err := s.Next(r.Context(), &req); err != nil {
+			writeRemoteError(w, err)
+		} else {
+			writeRemoteJSON(w, http.StatusOK, resp)
+		}
+	})
+	return mux
+}
+
+func readRemoteJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeRemoteJSON(w, http.StatusMethodNotAllowed, RemoteError{Error: "method not allowed"})
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeRemoteJSON(w, http.StatusBadRequest, RemoteError{Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeRemoteJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// RemoteStatusCode 错误对应的HTTP状态码
+func RemoteStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrRemoteEmptyDomain), errors.Is(err, ErrRemoteInvalidQuantum), errors.Is(err, ErrRangeNotContiguous):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrReachIdLimitation):
+		return http.StatusConflict
+	case errors.Is(err, ErrorDriverHasClosed), errors.Is(err, ErrorDriverHasNotInited), errors.Is(err, ErrIdRunOut),
+		errors.Is(err, ErrRenewCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func writeRemoteError(w http.ResponseWriter, err error) {
+	writeRemoteJSON(w, RemoteStatusCode(err), RemoteError{Error: err.Error()})
+}
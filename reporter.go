@@ -14,8 +14,8 @@ func getRenewStatus(err error) string {
 	return "ok"
 }
 
-func (e *engine) renewReport(currQuantum uint64, renewBegin z.MonoTimeDuration, err error) {
-	if !e.builder.visitor.GetEnableMonitor() {
+func (e *engine) renewReport(curr, currQuantum uint64, renewBegin z.MonoTimeDuration, err error) {
+	if !e.visitor.GetEnableMonitor() {
 		return
 	}
 	if err != nil {
@@ -23,45 +23,55 @@ func (e *engine) renewReport(currQuantum uint64, renewBegin z.MonoTimeDuration,
 		logbus.Error(w("renew error"), logbus.ErrorField(err), logbus.String("domain", e.domain))
 	} else {
 		_ = e.renewCount.Add(1)
-		if e.builder.visitor.GetDevelopment() {
-			logbus.Debug(w("renew ok"), logbus.Uint64("nextN", e.nextN),
-				logbus.Uint64("quantum", currQuantum), logbus.Uint64("nextMax", e.nextMax), logbus.String("domain", e.domain))
+		if e.visitor.GetDevelopment() {
+			logbus.Debug(w("renew ok"), logbus.Uint64("n", curr),
+				logbus.Uint64("quantum", currQuantum), logbus.Uint64("max", curr+currQuantum), logbus.String("domain", e.domain))
 		}
 	}
-	if e.builder.visitor.GetEnableTimeSummary() {
+	if e.visitor.GetEnableTimeSummary() {
 		_ = monitor.Timing("siid_renew_time", z.MonoSince(renewBegin), prometheus.Labels{"domain": e.domain, "status": getRenewStatus(err)})
 	} else {
 		_ = monitor.Count("siid_renew", 1, prometheus.Labels{"domain": e.domain, "status": getRenewStatus(err)})
 	}
+	if e.builder.breaker != nil {
+		_ = monitor.Gauge("siid_circuit_state", float64(e.circuitState()), prometheus.Labels{"domain": e.domain})
+	}
 }
 
 func (e *engine) nextReport(n int, nextBegin z.MonoTimeDuration, _ error) {
-	if !e.builder.visitor.GetEnableMonitor() {
+	if !e.visitor.GetEnableMonitor() {
 		return
 	}
 	cost := z.MonoSince(nextBegin)
-	if e.builder.visitor.GetEnableTimeSummary() {
+	if e.visitor.GetEnableTimeSummary() {
 		_ = monitor.Timing("siid_next_time", cost, prometheus.Labels{"domain": e.domain})
 	} else {
 		_ = monitor.Count("siid_next", int64(n), prometheus.Labels{"domain": e.domain})
 	}
-	if e.builder.visitor.GetEnableSlow() && cost >= e.builder.visitor.GetSlowQuery() {
+	if e.visitor.GetEnableSlow() && cost >= e.visitor.GetSlowQuery() {
 		logbus.Warn(w("next slow query"), logbus.Duration("cost", cost), logbus.String("domain", e.domain), logbus.Int("count", n))
 	}
 }
 
-func (e *engine) useNewQuantumReport() {
-	if !e.builder.visitor.GetEnableMonitor() {
+func (e *engine) useNewQuantumReport(max uint64) {
+	if !e.visitor.GetEnableMonitor() {
 		return
 	}
 	pl := prometheus.Labels{"domain": e.domain}
 	_ = monitor.Gauge("siid_quantum", float64(e.quantum), pl)
-	_ = monitor.Gauge("siid_max", float64(e.max), pl)
+	_ = monitor.Gauge("siid_max", float64(max), pl)
+}
+
+func (e *engine) leftReport(n uint64) {
+	if !e.visitor.GetEnableMonitor() {
+		return
+	}
+	_ = monitor.Gauge("siid_n_left", float64(regionLimitation(e.visitor)-n), prometheus.Labels{"domain": e.domain})
 }
 
-func (e *engine) leftReport() {
-	if !e.builder.visitor.GetEnableMonitor() {
+func (f *failoverDriver) renewReport(b *FailoverBackend, domain string, err error) {
+	if f.visitor != nil && !f.visitor.GetEnableMonitor() {
 		return
 	}
-	_ = monitor.Gauge("siid_n_left", float64(e.builder.visitor.GetLimitation()-e.n), prometheus.Labels{"domain": e.domain})
+	_ = monitor.Count("siid_failover_renew", 1, prometheus.Labels{"domain": domain, "backend": b.Name, "status": getRenewStatus(err)})
 }
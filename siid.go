@@ -10,13 +10,21 @@ var (
 	ErrIdRunOut             = errors.New("id run out")
 	ErrorDriverHasClosed    = errors.New("driver has closed")
 	ErrorDriverHasNotInited = errors.New("driver has not inited, call Builder.Prepare first")
+	ErrRenewCircuitOpen     = errors.New("renew circuit open")
+	ErrAdminNotSupported    = errors.New("driver does not implement AdminDriver")
+	ErrDomainNotFound       = errors.New("domain not found")
+	ErrDomainExists         = errors.New("domain already exists")
+	ErrCurrentBackwards     = errors.New("current id can not move backwards")
+	ErrDomainInUse          = errors.New("domain is in use by this builder")
+	ErrRangeNotContiguous   = errors.New("id range is not contiguous in region mode")
 )
 
 type Stats struct {
-	Current       uint64 // 当前id值
-	Max           uint64 // id最大值
-	RenewCount    uint64 // renew的次数
-	RenewErrCount uint64 // renew的错误次数，若>0，属于发生了严重错误
+	Current       uint64       // 当前id值
+	Max           uint64       // id最大值
+	RenewCount    uint64       // renew的次数
+	RenewErrCount uint64       // renew的错误次数，若>0，属于发生了严重错误
+	CircuitState  CircuitState // renew熔断状态
 }
 
 type Builder interface {
@@ -34,17 +42,40 @@ type Builder interface {
 	// domain 域，每种类型id，都拥有一个固定的域名，例如`player`
 	BuildWithOffset(domain string, offsetOnCreate uint64) (Engine, error)
 
+	// BuildWithOptions 建立Engine（新建或者返回已存在的Engine）
+	// opts domain级别的配置，覆盖Options中的同名配置及Options.DomainOptions中该domain的配置，仅在新建Engine时生效
+	BuildWithOptions(domain string, opts ...DomainOption) (Engine, error)
+
+	// Warmup 建立domains对应的Engine，并预取当前号段及下一号段，返回第一个失败的domain的错误
+	Warmup(ctx context.Context, domains ...string) error
+
 	// Range 遍历当前存在的所有的 domain 对应的 Engine
 	Range(func(domain string, engine Engine) bool)
+
+	// Admin 返回domain管理接口，Driver未实现AdminDriver时返回ErrAdminNotSupported
+	// 强制回退当前id或删除domain时，若本Builder中已存在该domain的Engine，返回ErrDomainInUse
+	Admin() (AdminDriver, error)
 }
 
 type Engine interface {
 	// Next 取唯一id
 	Next() (uint64, error)
 
+	// NextContext 取唯一id，ctx结束时放弃等待renew，返回包装了ctx.Err()的错误
+	// ctx的截止时间会传递给Driver.Renew
+	NextContext(ctx context.Context) (uint64, error)
+
+	// NextNContext 连续取n个唯一id，返回最后一个，ctx的处理同NextContext
+	NextNContext(ctx context.Context, n int) (uint64, error)
+
 	// MustNext 取唯一id，若发生错误，则会panic
 	MustNext() uint64
 
+	// NextRange 取一段连续的唯一id，返回[first, last]，共n个
+	// 当前号段足够时直接从当前号段中切分，否则向Driver申请一段长度为n的独立号段
+	// 多区域模式（RegionCount > 1）下本区域的id不连续，返回ErrRangeNotContiguous
+	NextRange(ctx context.Context, n uint64) (first, last uint64, err error)
+
 	// Stats 当前状态
 	Stats() Stats
 }
@@ -63,3 +94,35 @@ type Driver interface {
 	// 返回当前id
 	Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error)
 }
+
+// SegmentReturner Driver的可选接口，实现后Builder.Destroy会将未使用的号段归还给Driver
+type SegmentReturner interface {
+	// ReturnSegment 归还号段，若domain的当前id仍为max（即此后没有其他renew），则将其设置回n
+	// 返回是否归还成功，未归还成功不视为错误
+	ReturnSegment(ctx context.Context, domain string, n, max uint64) (bool, error)
+}
+
+// DomainInfo domain及其当前id
+type DomainInfo struct {
+	Domain  string
+	Current uint64
+}
+
+// AdminDriver Driver的可选接口，用于查看及修改domain，代替手工修改数据库
+type AdminDriver interface {
+	// ListDomains 返回所有domain，按domain排序
+	ListDomains(ctx context.Context) ([]DomainInfo, error)
+
+	// GetCurrent 返回domain的当前id，domain不存在时返回ErrDomainNotFound
+	GetCurrent(ctx context.Context, domain string) (uint64, error)
+
+	// SetCurrent 设置domain的当前id，force为false时只能前移，否则返回ErrCurrentBackwards
+	// 回退当前id会导致已分配的id被再次分配，仅在确认这些id未被使用时才能force
+	SetCurrent(ctx context.Context, domain string, current uint64, force bool) error
+
+	// CreateDomain 以current为当前id新建domain，domain已存在时返回ErrDomainExists
+	CreateDomain(ctx context.Context, domain string, current uint64) error
+
+	// DeleteDomain 删除domain，之后的renew会以offsetOnCreate重新创建该domain，domain不存在时返回ErrDomainNotFound
+	DeleteDomain(ctx context.Context, domain string) error
+}
@@ -0,0 +1,240 @@
+// Package siidtest 提供用于测试的内存Driver，可注入延迟、错误及panic，并记录每次Renew调用，
+// 便于在没有数据库的情况下测试renew失败的处理逻辑
+package siidtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sandwich-go/siid"
+)
+
+// Fault 一次Renew调用的故障
+type Fault struct {
+	Latency time.Duration // 额外延迟，ctx结束时提前返回ctx.Err()
+	Err     error         // 非nil时返回该错误
+	Panic   interface{}   // 非nil时以该值panic
+}
+
+// Call 一次Renew调用的记录
+type Call struct {
+	Domain         string
+	Quantum        uint64
+	OffsetOnCreate uint64
+	Current        uint64      // 成功时返回的当前id
+	Err            error       // 返回的错误
+	Panic          interface{} // 注入的panic
+}
+
+// Driver 内存Driver，同一个Driver可被多个Builder共享，模拟多个进程共用一个存储
+type Driver struct {
+	mu        sync.Mutex
+	domains   map[string]uint64
+	latency   time.Duration
+	faults    []Fault
+	failWith  error
+	calls     []Call
+	prepared  int
+	destroyed bool
+}
+
+var (
+	_ siid.Driver          = (*Driver)(nil)
+	_ siid.SegmentReturner = (*Driver)(nil)
+	_ siid.AdminDriver     = (*Driver)(nil)
+)
+
+// NewDriver 新建内存Driver
+func NewDriver() *Driver {
+	return &Driver{domains: make(map[string]uint64)}
+}
+
+// SetLatency 设置每次Renew的固定延迟
+func (d *Driver) SetLatency(latency time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.latency = latency
+}
+
+// Inject 注入故障，依次作用于之后的Renew调用，每次调用消耗一个
+func (d *Driver) Inject(faults ...Fault) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.faults = append(d.faults, faults...)
+}
+
+// FailWith 之后所有Renew调用均返回err，传入nil恢复正常
+// 先消耗Inject注入的故障
+func (d *Driver) FailWith(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failWith = err
+}
+
+// Calls 返回所有Renew调用的记录
+func (d *Driver) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	calls := make([]Call, len(d.calls))
+	copy(calls, d.calls)
+	return calls
+}
+
+// Current 返回domain的当前id
+func (d *Driver) Current(domain string) (uint64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.domains[domain]
+	return c, ok
+}
+
+// PrepareCount 返回Prepare被调用的次数
+func (d *Driver) PrepareCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.prepared
+}
+
+func (d *Driver) Prepare(_ context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.prepared++
+	d.destroyed = false
+	return nil
+}
+
+func (d *Driver) Destroy(_ context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.destroyed = true
+	return nil
+}
+
+func (d *Driver) nextFault() Fault {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f := Fault{Latency: d.latency, Err: d.failWith}
+	if len(d.faults) > 0 {
+		f = d.faults[0]
+		d.faults = d.faults[1:]
+		f.Latency += d.latency
+	}
+	return f
+}
+
+func (d *Driver) record(call Call) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, call)
+}
+
+func (d *Driver) Renew(ctx context.Context, domain string, quantum, offsetOnCreate uint64) (uint64, error) {
+	call := Call{Domain: domain, Quantum: quantum, OffsetOnCreate: offsetOnCreate}
+	if call.Err = ctx.Err(); call.Err != nil {
+		d.record(call)
+		return 0, call.Err
+	}
+	f := d.nextFault()
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-ctx.Done():
+			call.Err = ctx.Err()
+			d.record(call)
+			return 0, call.Err
+		}
+	}
+	if f.Panic != nil {
+		call.Panic = f.Panic
+		d.record(call)
+		panic(f.Panic)
+	}
+	if f.Err != nil {
+		call.Err = f.Err
+		d.record(call)
+		return 0, call.Err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.destroyed {
+		call.Err = siid.ErrorDriverHasClosed
+	} else {
+		c, ok := d.domains[domain]
+		if !ok {
+			c = offsetOnCreate
+		}
+		d.domains[domain] = c + quantum
+		call.Current = c
+	}
+	d.calls = append(d.calls, call)
+	return call.Current, call.Err
+}
+
+func (d *Driver) ReturnSegment(_ context.Context, domain string, n, max uint64) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if c, ok := d.domains[domain]; !ok || c != max {
+		return false, nil
+	}
+	d.domains[domain] = n
+	return true, nil
+}
+
+func (d *Driver) ListDomains(_ context.Context) ([]siid.DomainInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	domains := make([]siid.DomainInfo, 0, len(d.domains))
+	for domain, c := range d.domains {
+		domains = append(domains, siid.DomainInfo{Domain: domain, Current: c})
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Domain < domains[j].Domain })
+	return domains, nil
+}
+
+func (d *Driver) GetCurrent(_ context.Context, domain string) (uint64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.domains[domain]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", siid.ErrDomainNotFound, domain)
+	}
+	return c, nil
+}
+
+func (d *Driver) SetCurrent(_ context.Context, domain string, current uint64, force bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.domains[domain]
+	if !ok {
+		return fmt.Errorf("%w: %s", siid.ErrDomainNotFound, domain)
+	}
+	if !force && c > current {
+		return fmt.Errorf("%w: domain %s, current %d, set %d", siid.ErrCurrentBackwards, domain, c, current)
+	}
+	d.domains[domain] = current
+	return nil
+}
+
+func (d *Driver) CreateDomain(_ context.Context, domain string, current uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.domains[domain]; ok {
+		return fmt.Errorf("%w: %s", siid.ErrDomainExists, domain)
+	}
+	d.domains[domain] = current
+	return nil
+}
+
+func (d *Driver) DeleteDomain(_ context.Context, domain string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.domains[domain]; !ok {
+		return fmt.Errorf("%w: %s", siid.ErrDomainNotFound, domain)
+	}
+	delete(d.domains, domain)
+	return nil
+}
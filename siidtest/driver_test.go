@@ -0,0 +1,73 @@
+package siidtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandwich-go/siid"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newEngine(d *Driver, opts ...siid.Option) siid.Engine {
+	opts = append([]siid.Option{siid.WithDevelopment(false), siid.WithRenewRetryDelay(time.Millisecond)}, opts...)
+	b := siid.NewWithDriver(d, siid.NewConfig(opts...))
+	So(b.Prepare(context.Background()), ShouldBeNil)
+	e, err := b.Build("test")
+	So(err, ShouldBeNil)
+	return e
+}
+
+func TestDriver(t *testing.T) {
+	Convey("siidtest driver", t, func() {
+		d := NewDriver()
+		errRenew := errors.New("renew error")
+
+		Convey("should record calls", func() {
+			e := newEngine(d, siid.WithOffsetWhenAutoCreateDomain(100), siid.WithInitialQuantum(10), siid.WithMinQuantum(10))
+			id, err := e.Next()
+			So(err, ShouldBeNil)
+			So(id, ShouldEqual, 101)
+			calls := d.Calls()
+			So(len(calls), ShouldBeGreaterThanOrEqualTo, 1)
+			So(calls[0], ShouldResemble, Call{Domain: "test", Quantum: 10, OffsetOnCreate: 100, Current: 100})
+			current, ok := d.Current("test")
+			So(ok, ShouldBeTrue)
+			So(current, ShouldBeGreaterThanOrEqualTo, 110)
+		})
+
+		Convey("should retry scripted errors and panics", func() {
+			d.Inject(Fault{Err: errRenew}, Fault{Panic: "boom"})
+			e := newEngine(d)
+			_, err := e.Next()
+			So(err, ShouldBeNil)
+			calls := d.Calls()
+			So(calls[0].Err, ShouldEqual, errRenew)
+			So(calls[1].Panic, ShouldEqual, "boom")
+			So(calls[2].Err, ShouldBeNil)
+			So(e.Stats().RenewErrCount, ShouldBeZeroValue)
+		})
+
+		Convey("should run out when renew keeps failing", func() {
+			d.FailWith(errRenew)
+			e := newEngine(d, siid.WithRenewRetry(2))
+			_, err := e.Next()
+			So(err, ShouldEqual, siid.ErrIdRunOut)
+			So(e.Stats().RenewErrCount, ShouldBeGreaterThan, 0)
+
+			d.FailWith(nil)
+			_, err = e.Next()
+			So(err, ShouldBeNil)
+		})
+
+		Convey("should honor context with latency", func() {
+			d.SetLatency(time.Hour)
+			e := newEngine(d)
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := e.NextContext(ctx)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+	})
+}
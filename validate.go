@@ -0,0 +1,116 @@
+package siid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sandwich-go/logbus"
+)
+
+var ErrInvalidOptions = errors.New("invalid options")
+
+// optionsError 汇总所有不合法的配置
+type optionsError struct {
+	problems []error
+}
+
+func (e *optionsError) Error() string {
+	s := make([]string, 0, len(e.problems))
+	for _, p := range e.problems {
+		s = append(s, p.Error())
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidOptions, strings.Join(s, "; "))
+}
+
+// Is 匹配ErrInvalidOptions及任一问题对应的错误，例如ErrInvalidRegion
+func (e *optionsError) Is(target error) bool {
+	if target == ErrInvalidOptions {
+		return true
+	}
+	for _, p := range e.problems {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 校验配置，返回的错误包含所有不合法的配置，errors.Is(err, ErrInvalidOptions)为true
+func (cc *Options) Validate() error { return validateOptions(cc) }
+
+func validateOptions(visitor OptionsVisitor) error {
+	var problems []error
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+	if visitor.GetMinQuantum() == 0 {
+		add("MinQuantum is 0")
+	}
+	if visitor.GetMaxQuantum() < visitor.GetMinQuantum() {
+		add("MaxQuantum %d less than MinQuantum %d", visitor.GetMaxQuantum(), visitor.GetMinQuantum())
+	}
+	if p := visitor.GetRenewPercent(); p <= 0 || p > 100 {
+		add("RenewPercent %d out of range (0, 100]", p)
+	}
+	if visitor.GetRenewTimeout() <= 0 {
+		add("RenewTimeout %s is not positive", visitor.GetRenewTimeout())
+	}
+	if visitor.GetSegmentDuration() <= 0 {
+		add("SegmentDuration %s is not positive", visitor.GetSegmentDuration())
+	}
+	if visitor.GetLimitation() <= visitor.GetOffsetWhenAutoCreateDomain() {
+		add("Limitation %d not greater than OffsetWhenAutoCreateDomain %d", visitor.GetLimitation(), visitor.GetOffsetWhenAutoCreateDomain())
+	}
+	if visitor.GetCircuitBreakerThreshold() > 0 && visitor.GetCircuitBreakerCoolDown() <= 0 {
+		add("CircuitBreakerCoolDown %s is not positive", visitor.GetCircuitBreakerCoolDown())
+	}
+	if err := checkRegion(visitor); err != nil {
+		problems = append(problems, err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &optionsError{problems: problems}
+}
+
+// clampOptions 将可修正的不合法配置修正为最接近的合法值
+func clampOptions(cc OptionsInterface) {
+	clamp := func(name string, from, to interface{}, opt Option) {
+		logbus.Warn(w("clamp option"), logbus.String("option", name), logbus.Any("from", from), logbus.Any("to", to))
+		cc.ApplyOption(opt)
+	}
+	defaults := newDefaultOptions()
+	if cc.GetMinQuantum() == 0 {
+		clamp("MinQuantum", cc.GetMinQuantum(), uint64(1), WithMinQuantum(1))
+	}
+	if cc.GetMaxQuantum() < cc.GetMinQuantum() {
+		clamp("MaxQuantum", cc.GetMaxQuantum(), cc.GetMinQuantum(), WithMaxQuantum(cc.GetMinQuantum()))
+	}
+	// InitialQuantum使用默认值时常超出调整后的[MinQuantum, MaxQuantum]，属于正常配置，不输出日志
+	if q := cc.GetInitialQuantum(); q < cc.GetMinQuantum() {
+		cc.ApplyOption(WithInitialQuantum(cc.GetMinQuantum()))
+	} else if q > cc.GetMaxQuantum() {
+		cc.ApplyOption(WithInitialQuantum(cc.GetMaxQuantum()))
+	}
+	if p := cc.GetRenewPercent(); p <= 0 {
+		clamp("RenewPercent", p, 1, WithRenewPercent(1))
+	} else if p > 100 {
+		clamp("RenewPercent", p, 100, WithRenewPercent(100))
+	}
+	for _, d := range []struct {
+		name string
+		v    time.Duration
+		def  time.Duration
+		with func(time.Duration) Option
+	}{
+		{"RenewTimeout", cc.GetRenewTimeout(), defaults.RenewTimeout, WithRenewTimeout},
+		{"SegmentDuration", cc.GetSegmentDuration(), defaults.SegmentDuration, WithSegmentDuration},
+		{"CircuitBreakerCoolDown", cc.GetCircuitBreakerCoolDown(), defaults.CircuitBreakerCoolDown, WithCircuitBreakerCoolDown},
+	} {
+		if d.v <= 0 {
+			clamp(d.name, d.v, d.def, d.with(d.def))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package siid
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateOptions(t *testing.T) {
+	Convey("validate options", t, func() {
+		So(NewConfig().Validate(), ShouldBeNil)
+		// InitialQuantum超出[MinQuantum, MaxQuantum]时使用时修正，不视为错误
+		So(NewConfig(WithMinQuantum(100)).Validate(), ShouldBeNil)
+		So(NewConfig(WithInitialQuantum(5000)).Validate(), ShouldBeNil)
+
+		Convey("each problem", func() {
+			for _, opts := range [][]Option{
+				{WithMinQuantum(0)},
+				{WithMinQuantum(100), WithMaxQuantum(10)},
+				{WithRenewPercent(0)},
+				{WithRenewPercent(101)},
+				{WithRenewTimeout(0)},
+				{WithSegmentDuration(0)},
+				{WithLimitation(100), WithOffsetWhenAutoCreateDomain(100)},
+				{WithCircuitBreakerThreshold(3), WithCircuitBreakerCoolDown(0)},
+			} {
+				So(errors.Is(NewConfig(opts...).Validate(), ErrInvalidOptions), ShouldBeTrue)
+			}
+		})
+
+		Convey("aggregated", func() {
+			err := NewConfig(WithMinQuantum(100), WithMaxQuantum(10), WithRenewPercent(0), WithRegionCount(2), WithRegionIndex(2)).Validate()
+			So(errors.Is(err, ErrInvalidOptions), ShouldBeTrue)
+			So(errors.Is(err, ErrInvalidRegion), ShouldBeTrue)
+			So(strings.Count(err.Error(), ";"), ShouldEqual, 2)
+			So(err.Error(), ShouldContainSubstring, "MaxQuantum 10 less than MinQuantum 100")
+			So(err.Error(), ShouldContainSubstring, "RenewPercent 0")
+		})
+
+		Convey("prepare", func() {
+			b := NewWithDriver(getDummyDriver(), NewConfig(WithRenewPercent(0)))
+			So(errors.Is(b.Prepare(context.Background()), ErrInvalidOptions), ShouldBeTrue)
+		})
+
+		Convey("domain options", func() {
+			b := NewWithDriver(getDummyDriver(), NewConfig(WithDevelopment(false)))
+			So(b.Prepare(context.Background()), ShouldBeNil)
+			_, err := b.BuildWithOptions("order", WithDomainMaxQuantum(1))
+			So(errors.Is(err, ErrInvalidOptions), ShouldBeTrue)
+		})
+
+		Convey("clamp", func() {
+			opts := NewConfig(WithClampOptions(true), WithMinQuantum(0), WithMaxQuantum(0), WithRenewPercent(200),
+				WithRenewTimeout(-time.Second), WithSegmentDuration(0), WithLimitation(10), WithOffsetWhenAutoCreateDomain(10))
+			b := NewWithDriver(getDummyDriver(), opts)
+			// 修正的是副本，调用方的Options不变
+			So(opts.GetMinQuantum(), ShouldEqual, 0)
+			clamped := b.(*builder).visitor
+			So(clamped.GetMinQuantum(), ShouldEqual, 1)
+			So(clamped.GetMaxQuantum(), ShouldEqual, 1)
+			So(clamped.GetInitialQuantum(), ShouldEqual, 1)
+			So(clamped.GetRenewPercent(), ShouldEqual, 100)
+			So(clamped.GetRenewTimeout(), ShouldEqual, newDefaultOptions().RenewTimeout)
+			So(clamped.GetSegmentDuration(), ShouldEqual, newDefaultOptions().SegmentDuration)
+			// Limitation无法修正
+			err := b.Prepare(context.Background())
+			So(errors.Is(err, ErrInvalidOptions), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "Limitation")
+			So(err.Error(), ShouldNotContainSubstring, "MinQuantum")
+		})
+	})
+}